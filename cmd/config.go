@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"strings"
 	"time"
 )
 
@@ -18,13 +19,30 @@ const (
 	googleRandomStateEnv  = "GOOGLE_RANDOM_STATE"
 	jwtSecretEnv          = "JWT_SECRET"
 	jwtExpPeriodEnv       = "JWT_EXP_PERIOD_DURATION"
+	refreshExpPeriodEnv   = "REFRESH_EXP_PERIOD_DURATION"
 	appURLEnv             = "APP_URL"
+
+	scheduleSlotStepEnv = "SCHEDULE_SLOT_STEP"
+
+	bookingHoldTTLEnv        = "BOOKING_HOLD_TTL"
+	waitlistHoldTTLEnv       = "WAITLIST_HOLD_TTL"
+	waitlistSweepIntervalEnv = "WAITLIST_SWEEP_INTERVAL"
+
+	adminUserIDsEnv = "ADMIN_USER_IDS"
 )
 
 const (
 	appURlDefault       = "http://localhost"
 	jwtSecretDefault    = "abrakcskwq1323dns2"
 	jwtExpPeriodDefault = time.Hour
+	// Sessions unused for this long are logged out
+	refreshExpPeriodDefault = 30 * 24 * time.Hour
+
+	scheduleSlotStepDefault = 15 * time.Minute
+
+	bookingHoldTTLDefault        = 10 * time.Minute
+	waitlistHoldTTLDefault       = 15 * time.Minute
+	waitlistSweepIntervalDefault = time.Minute
 )
 
 var (
@@ -40,6 +58,15 @@ type Config struct {
 	GoogleRandomState string
 	JWTSecret         string
 	JWTExpPeriod      time.Duration
+	RefreshExpPeriod  time.Duration
+	SlotStep          time.Duration
+	// BookingHoldTTL is how long a checkout hold blocks its slot.
+	BookingHoldTTL time.Duration
+	// WaitlistHoldTTL is how long a waitlisted customer has to claim a freed slot.
+	WaitlistHoldTTL       time.Duration
+	WaitlistSweepInterval time.Duration
+	// AdminUserIDs are the users allowed to manage the category taxonomy.
+	AdminUserIDs []string
 }
 
 func LoadConfig() *Config {
@@ -51,6 +78,11 @@ func LoadConfig() *Config {
 
 	viper.SetDefault(jwtSecretEnv, jwtSecretDefault)
 	viper.SetDefault(jwtExpPeriodEnv, jwtExpPeriodDefault)
+	viper.SetDefault(refreshExpPeriodEnv, refreshExpPeriodDefault)
+	viper.SetDefault(scheduleSlotStepEnv, scheduleSlotStepDefault)
+	viper.SetDefault(bookingHoldTTLEnv, bookingHoldTTLDefault)
+	viper.SetDefault(waitlistHoldTTLEnv, waitlistHoldTTLDefault)
+	viper.SetDefault(waitlistSweepIntervalEnv, waitlistSweepIntervalDefault)
 
 	return &Config{
 		Port:       viper.GetInt(httpPortEnv),
@@ -66,5 +98,44 @@ func LoadConfig() *Config {
 		GoogleRandomState: viper.GetString(googleRandomStateEnv),
 		JWTSecret:         viper.GetString(jwtSecretEnv),
 		JWTExpPeriod:      viper.GetDuration(jwtExpPeriodEnv),
+		RefreshExpPeriod:  viper.GetDuration(refreshExpPeriodEnv),
+		SlotStep:          viper.GetDuration(scheduleSlotStepEnv),
+
+		BookingHoldTTL:        viper.GetDuration(bookingHoldTTLEnv),
+		WaitlistHoldTTL:       viper.GetDuration(waitlistHoldTTLEnv),
+		WaitlistSweepInterval: viper.GetDuration(waitlistSweepIntervalEnv),
+
+		AdminUserIDs: splitList(viper.GetString(adminUserIDsEnv)),
+	}
+}
+
+// Validate checks the settings the service cannot run with. The durations must be positive: the slot step
+// drives the availability loops and the sweep interval a ticker.
+func (c *Config) Validate() error {
+	durations := []struct {
+		env   string
+		value time.Duration
+	}{
+		{scheduleSlotStepEnv, c.SlotStep},
+		{bookingHoldTTLEnv, c.BookingHoldTTL},
+		{waitlistHoldTTLEnv, c.WaitlistHoldTTL},
+		{waitlistSweepIntervalEnv, c.WaitlistSweepInterval},
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			return fmt.Errorf("%s must be a positive duration, got %s", d.env, d.value)
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma separated setting, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
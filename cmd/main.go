@@ -8,20 +8,31 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // business timezones must resolve in minimal runtime images
 
 	"booking-service/internal/api/rest"
 	"booking-service/internal/api/rest/auth"
+	"booking-service/internal/api/rest/bookingrules"
 	"booking-service/internal/api/rest/bookings"
 	business_account "booking-service/internal/api/rest/business-account"
 	"booking-service/internal/api/rest/middlewares"
+	schedulesAPI "booking-service/internal/api/rest/schedules"
 	"booking-service/internal/api/rest/services"
 	"booking-service/internal/api/rest/specialists"
+	taxonomyAPI "booking-service/internal/api/rest/taxonomy"
 	user_account "booking-service/internal/api/rest/user-account"
+	waitlistAPI "booking-service/internal/api/rest/waitlist"
 	bStore "booking-service/internal/store/bookings"
 	"booking-service/internal/store/business_accounts"
 	servicesStore "booking-service/internal/store/services"
+	sessionsStore "booking-service/internal/store/sessions"
+	specialistsStore "booking-service/internal/store/specialists"
+	taxonomyStore "booking-service/internal/store/taxonomy"
 	"booking-service/internal/store/users"
+	waitlistStore "booking-service/internal/store/waitlist"
+	"booking-service/internal/waitlist"
 	"booking-service/pkg/db"
+	utoken "booking-service/pkg/utils/token"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
@@ -29,6 +40,10 @@ import (
 
 func main() {
 	cfg := LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Error().Err(err).Msg("Invalid configuration")
+		return
+	}
 
 	errChan := make(chan error)
 
@@ -46,11 +61,24 @@ func main() {
 	businessAccountsStore := business_accounts.NewStore(dbConn.ReadPool, dbConn.WritePool)
 	bookingsStore := bStore.NewStore(dbConn.ReadPool, dbConn.WritePool)
 	servicesStore := servicesStore.NewStore(dbConn.ReadPool, dbConn.WritePool)
+	waitlistStore := waitlistStore.NewStore(dbConn.ReadPool, dbConn.WritePool)
+	specialistsStore := specialistsStore.NewStore(dbConn.ReadPool, dbConn.WritePool)
+	taxonomyStore := taxonomyStore.NewStore(dbConn.ReadPool, dbConn.WritePool)
+	sessionsStore := sessionsStore.NewStore(dbConn.ReadPool, dbConn.WritePool)
+
+	offerer := waitlist.NewOfferer(bookingsStore, waitlistStore, cfg.WaitlistHoldTTL)
+	// The sweeper expires both checkout holds and waitlist offers
+	sweepCtx, stopSweep := context.WithCancel(log.Logger.WithContext(ctx))
+	defer stopSweep()
+	go offerer.Run(sweepCtx, cfg.WaitlistSweepInterval)
+
+	router := setUpRouter(cfg, usersStore, businessAccountsStore, bookingsStore, servicesStore, waitlistStore,
+		specialistsStore, taxonomyStore, sessionsStore, offerer)
 
 	go func() {
 		server := &http.Server{
 			Addr:              fmt.Sprintf(":%d", cfg.Port),
-			Handler:           setUpRouter(cfg, usersStore, businessAccountsStore, bookingsStore, servicesStore),
+			Handler:           router,
 			ReadHeaderTimeout: 2 * time.Second,
 		}
 		log.Info().Msgf("Starting api service at port %d", cfg.Port)
@@ -69,25 +97,38 @@ func main() {
 	}
 }
 
-func setUpRouter(cnf *Config, usersStore users.Store, businessAccountsStore business_accounts.Store, bookingsStore bStore.Store, servicesStore servicesStore.Store) *mux.Router {
-	authMiddleware := middlewares.NewJWTMiddleware(cnf.JWTSecret)
-	authHandler := auth.NewHandler(cnf.GoogleLoginConfig, cnf.GoogleRandomState, cnf.JWTSecret, cnf.JWTExpPeriod, usersStore)
-	specialistsHandler := specialists.NewHandler()
+func setUpRouter(cnf *Config, usersStore users.Store, businessAccountsStore business_accounts.Store, bookingsStore bStore.Store, servicesStore servicesStore.Store,
+	waitlistStore waitlistStore.Store, specialistsStore specialistsStore.Store, taxonomyStore taxonomyStore.Store,
+	sessionsStore sessionsStore.Store, offerer *waitlist.Offerer) *mux.Router {
+	tokens := utoken.NewService(cnf.JWTSecret, cnf.JWTExpPeriod)
+	authMiddleware := middlewares.NewJWTMiddleware(tokens, sessionsStore)
+	authHandler := auth.NewHandler(cnf.GoogleLoginConfig, cnf.GoogleRandomState, tokens, cnf.RefreshExpPeriod, usersStore, bookingsStore, sessionsStore)
+	specialistsHandler := specialists.NewHandler(specialistsStore, businessAccountsStore, servicesStore, bookingsStore, taxonomyStore, cnf.SlotStep)
 	specialistsRouter := specialists.NewRouter(specialistsHandler, authMiddleware.Middleware)
 
-	authRouter := auth.NewRouter(authHandler)
-	bookingsHandler := bookings.NewHandler(bookingsStore)
+	authRouter := auth.NewRouter(authHandler, authMiddleware.Middleware)
+	bookingRules := bookingrules.NewLoader(servicesStore, businessAccountsStore, specialistsStore)
+	bookingsHandler := bookings.NewHandler(bookingsStore, businessAccountsStore, specialistsStore, bookingRules, offerer, cnf.BookingHoldTTL)
 	bookingsRouter := bookings.NewRouter(bookingsHandler, authMiddleware.Middleware)
 
-	businessAccountHandler := business_account.NewHandler(businessAccountsStore)
+	businessAccountHandler := business_account.NewHandler(businessAccountsStore, bookingsStore, bookingRules, usersStore)
 	businessAccountRouter := business_account.NewRouter(businessAccountHandler, authMiddleware.Middleware)
 
 	userAccountHandler := user_account.NewHandler(usersStore)
 	userAccountRouter := user_account.NewRouter(userAccountHandler, authMiddleware.Middleware)
 
-	servicesHandler := services.NewHandler(servicesStore, businessAccountsStore)
+	servicesHandler := services.NewHandler(servicesStore, businessAccountsStore, taxonomyStore)
 	servicesRouter := services.NewRouter(servicesHandler, authMiddleware.Middleware)
 
+	schedulesHandler := schedulesAPI.NewHandler(servicesStore, bookingsStore, businessAccountsStore, specialistsStore, cnf.SlotStep)
+	schedulesRouter := schedulesAPI.NewRouter(schedulesHandler, authMiddleware.Middleware)
+
+	waitlistHandler := waitlistAPI.NewHandler(waitlistStore, bookingsStore, servicesStore, offerer)
+	waitlistRouter := waitlistAPI.NewRouter(waitlistHandler, authMiddleware.Middleware)
+
+	taxonomyHandler := taxonomyAPI.NewHandler(taxonomyStore, cnf.AdminUserIDs)
+	taxonomyRouter := taxonomyAPI.NewRouter(taxonomyHandler, authMiddleware.Middleware)
+
 	routes := []rest.Register{
 		authRouter,
 		specialistsRouter,
@@ -95,6 +136,9 @@ func setUpRouter(cnf *Config, usersStore users.Store, businessAccountsStore busi
 		businessAccountRouter,
 		userAccountRouter,
 		servicesRouter,
+		schedulesRouter,
+		waitlistRouter,
+		taxonomyRouter,
 	}
 	return rest.NewRouter(routes)
 }
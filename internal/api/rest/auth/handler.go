@@ -1,14 +1,17 @@
 package auth
 
 import (
-	"context"
 	"encoding/json"
-	"io"
+	"errors"
 	"net/http"
 	"time"
 
 	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/sessions"
 	"booking-service/internal/store/users"
+	utoken "booking-service/pkg/utils/token"
+
 	"github.com/rs/zerolog/log"
 	"golang.org/x/oauth2"
 )
@@ -18,19 +21,24 @@ const googleGetUserURL = "https://www.googleapis.com/oauth2/v2/userinfo"
 type Handler struct {
 	googleConfig oauth2.Config
 	randomState  string
-	jwtSecret    string
-	jwtExpPeriod time.Duration
-	uStore       users.Store
+	tokens       *utoken.Service
+	// refreshExpPeriod is how long a session lasts without its refresh token being used.
+	refreshExpPeriod time.Duration
+	uStore           users.Store
+	bStore           bookings.Store
+	sessionsStore    sessions.Store
 }
 
-func NewHandler(googleConfig oauth2.Config, randomState, jwtSecret string, jwtExpPeriod time.Duration,
-	uStore users.Store) *Handler {
+func NewHandler(googleConfig oauth2.Config, randomState string, tokens *utoken.Service, refreshExpPeriod time.Duration,
+	uStore users.Store, bStore bookings.Store, sessionsStore sessions.Store) *Handler {
 	return &Handler{
-		googleConfig: googleConfig,
-		randomState:  randomState,
-		jwtSecret:    jwtSecret,
-		jwtExpPeriod: jwtExpPeriod,
-		uStore:       uStore,
+		googleConfig:     googleConfig,
+		randomState:      randomState,
+		tokens:           tokens,
+		refreshExpPeriod: refreshExpPeriod,
+		uStore:           uStore,
+		bStore:           bStore,
+		sessionsStore:    sessionsStore,
 	}
 }
 
@@ -50,7 +58,7 @@ func (h *Handler) GoogleCallback(resp http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := req.Context()
 
 	// Exchange code for token
 	code := req.FormValue("code")
@@ -62,6 +70,7 @@ func (h *Handler) GoogleCallback(resp http.ResponseWriter, req *http.Request) {
 			helpers.NewErrorResponse("Failed to exchange token", helpers.ExchangeTokenErr),
 			http.StatusInternalServerError,
 		)
+		return
 	}
 
 	// Get user info
@@ -74,18 +83,12 @@ func (h *Handler) GoogleCallback(resp http.ResponseWriter, req *http.Request) {
 			helpers.NewErrorResponse("Failed to get user info", helpers.AuthUserInfoErr),
 			http.StatusInternalServerError,
 		)
-	}
-
-	defer userResp.Body.Close()
-
-	var userInfo *UserInfo
-	body, err := io.ReadAll(req.Body)
-	if err != nil {
 		return
 	}
-	defer req.Body.Close()
+	defer userResp.Body.Close()
 
-	if err = json.Unmarshal(body, &userInfo); err != nil {
+	var userInfo UserInfo
+	if err := json.NewDecoder(userResp.Body).Decode(&userInfo); err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("Failed to decode user info")
 		helpers.WriteErrorResponse(
 			resp,
@@ -101,31 +104,85 @@ func (h *Handler) GoogleCallback(resp http.ResponseWriter, req *http.Request) {
 			helpers.NewErrorResponse("Email is required", helpers.InvalidEmailErr),
 			http.StatusBadRequest,
 		)
+		return
 	}
 
-	userID, err := h.uStore.GetUserIdByEmail(ctx, userInfo.Email)
+	userID, created, ok := h.signIn(resp, req, userInfo)
+	if !ok {
+		return
+	}
+
+	// Appointments the business added for this person as a guest become theirs once the email is verified
+	if userInfo.VerifiedEmail {
+		if _, err := h.bStore.LinkGuestBookings(ctx, userID, userInfo.Email); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("Failed to link guest bookings to user: %s", userID)
+		}
+	}
+
+	tokens, err := h.startSession(ctx, userID, time.Now())
 	if err != nil {
-		log.Ctx(ctx).Error().Err(err).Msgf("Failed to get user id by email: %s", userInfo.Email)
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to generate tokens for user: %s", userID)
 		helpers.WriteErrorResponse(
 			resp,
-			helpers.NewErrorResponse("Failed to get user id", helpers.GetUserIdErr),
+			helpers.NewErrorResponse("Failed to generate token", helpers.GenerateTokenErr),
 			http.StatusInternalServerError,
 		)
 		return
 	}
 
-	jwtToken, err := generateJWT(userID, h.jwtSecret, h.jwtExpPeriod)
+	status := http.StatusOK
+	if created {
+		tokens.NewUser = true
+		status = http.StatusCreated
+	}
+	helpers.WriteData(ctx, resp, tokens, status)
+}
+
+// signIn returns the user of the Google account, signing them up on their first login. Accounts that
+// predate linked identities are found by their email. It also returns whether the user was created.
+// Error responses are written here.
+func (h *Handler) signIn(resp http.ResponseWriter, req *http.Request, userInfo UserInfo) (string, bool, bool) {
+	ctx := req.Context()
+
+	userID, err := h.uStore.GetUserIdByIdentity(ctx, users.ProviderGoogle, userInfo.ID)
+	if err == nil {
+		return userID, false, true
+	}
+	if !errors.Is(err, users.ErrUserNotFound) {
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to get user id by google account: %s", userInfo.ID)
+		helpers.WriteErrorResponse(
+			resp,
+			helpers.NewErrorResponse("Failed to get user id", helpers.GetUserIdErr),
+			http.StatusInternalServerError,
+		)
+		return "", false, false
+	}
+
+	if userInfo.ID == "" || !userInfo.VerifiedEmail {
+		helpers.WriteErrorResponse(
+			resp,
+			helpers.NewErrorResponse("A verified Google email is required to sign up", helpers.InvalidEmailErr),
+			http.StatusForbidden,
+		)
+		return "", false, false
+	}
+
+	identity := users.Identity{Provider: users.ProviderGoogle, Subject: userInfo.ID, Email: userInfo.Email}
+	userID, created, err := h.uStore.SignUp(ctx, identity, &users.User{
+		Username:   userInfo.Name,
+		FirstName:  userInfo.GivenName,
+		LastName:   userInfo.FamilyName,
+		PictureURL: userInfo.Picture,
+	})
 	if err != nil {
-		log.Ctx(ctx).Error().Err(err).Msgf("Failed to generate JWT token for user: %s", userID)
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to sign up user: %s", userInfo.Email)
 		helpers.WriteErrorResponse(
 			resp,
-			helpers.NewErrorResponse("Failed to generate token", helpers.GenerateTokenErr),
+			helpers.NewErrorResponse("Failed to sign up", helpers.UsersStoreErr),
 			http.StatusInternalServerError,
 		)
-		return
+		return "", false, false
 	}
 
-	helpers.WriteData(ctx, resp, map[string]string{
-		"access_token": jwtToken,
-	}, http.StatusOK)
+	return userID, created, true
 }
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"booking-service/internal/store/users"
+)
+
+type fakeUsers struct {
+	users.Store
+	identities map[string]string
+	lookupErr  error
+	signedUp   []users.Identity
+}
+
+func (f *fakeUsers) GetUserIdByIdentity(_ context.Context, _, subject string) (string, error) {
+	if f.lookupErr != nil {
+		return "", f.lookupErr
+	}
+	if userID, ok := f.identities[subject]; ok {
+		return userID, nil
+	}
+	return "", users.ErrUserNotFound
+}
+
+func (f *fakeUsers) SignUp(_ context.Context, identity users.Identity, _ *users.User) (string, bool, error) {
+	f.signedUp = append(f.signedUp, identity)
+	return "new-user", true, nil
+}
+
+func TestHandler_signIn(t *testing.T) {
+	tests := []struct {
+		name        string
+		userInfo    UserInfo
+		lookupErr   error
+		wantUserID  string
+		wantCreated bool
+		wantStatus  int
+	}{
+		{name: "known identity", userInfo: UserInfo{ID: "known", Email: "known@example.com"}, wantUserID: "user-1"},
+		{name: "first login", userInfo: UserInfo{ID: "new", Email: "new@example.com", VerifiedEmail: true},
+			wantUserID: "new-user", wantCreated: true},
+		{name: "first login with unverified email", userInfo: UserInfo{ID: "new", Email: "new@example.com"},
+			wantStatus: http.StatusForbidden},
+		{name: "first login without account id", userInfo: UserInfo{Email: "new@example.com", VerifiedEmail: true},
+			wantStatus: http.StatusForbidden},
+		{name: "failed lookup", userInfo: UserInfo{ID: "known", Email: "known@example.com"}, lookupErr: errors.New("down"),
+			wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeUsers{identities: map[string]string{"known": "user-1"}, lookupErr: tt.lookupErr}
+			h := &Handler{uStore: store}
+			resp := httptest.NewRecorder()
+
+			userID, created, ok := h.signIn(resp, httptest.NewRequest(http.MethodGet, "/", nil), tt.userInfo)
+
+			if tt.wantStatus != 0 {
+				if ok || resp.Code != tt.wantStatus {
+					t.Fatalf("signIn() ok = %v, status = %d, want failure with %d", ok, resp.Code, tt.wantStatus)
+				}
+				if len(store.signedUp) > 0 {
+					t.Errorf("signIn() signed up %v on failure", store.signedUp)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("signIn() failed with status %d", resp.Code)
+			}
+			if userID != tt.wantUserID || created != tt.wantCreated {
+				t.Errorf("signIn() = %q, %v, want %q, %v", userID, created, tt.wantUserID, tt.wantCreated)
+			}
+		})
+	}
+}
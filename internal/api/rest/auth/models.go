@@ -9,3 +9,17 @@ type UserInfo struct {
 	FamilyName    string `json:"family_name"`
 	Picture       string `json:"picture"`
 }
+
+// TokenResponse is answered to logins and refreshes. The refresh token is shown only once.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn is the lifetime of the access token in seconds.
+	ExpiresIn int64 `json:"expires_in"`
+	// NewUser is set on the first login, which signs the user up.
+	NewUser bool `json:"new_user,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -7,14 +7,21 @@ import (
 )
 
 type Router struct {
-	handler *Handler
+	handler        *Handler
+	authMiddleware mux.MiddlewareFunc
 }
 
-func NewRouter(handler *Handler) Router {
-	return Router{handler: handler}
+func NewRouter(handler *Handler, authMiddleware mux.MiddlewareFunc) Router {
+	return Router{handler: handler, authMiddleware: authMiddleware}
 }
 
 func (r Router) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/google-login", r.handler.GoogleLogin).Methods(http.MethodPost)
 	router.HandleFunc("/google-callback", r.handler.GoogleCallback).Methods(http.MethodPost)
+
+	// Refresh and logout are authorized by the refresh token in the body, access tokens may have expired
+	authRouter := router.PathPrefix("/auth").Subrouter()
+	authRouter.HandleFunc("/refresh", r.handler.Refresh).Methods(http.MethodPost)
+	authRouter.HandleFunc("/logout", r.handler.Logout).Methods(http.MethodPost)
+	authRouter.Handle("/logout-all", r.authMiddleware(http.HandlerFunc(r.handler.LogoutAll))).Methods(http.MethodPost)
 }
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/sessions"
+	utoken "booking-service/pkg/utils/token"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Refresh exchanges a refresh token for a new access and refresh token. Every refresh token can be used once;
+// using one again logs its session out, as the token may have been stolen.
+func (h *Handler) Refresh(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	refreshToken, ok := decodeRefreshToken(resp, req)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	newToken, newHash, err := utoken.NewRefreshToken()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Failed to generate refresh token")
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to generate token", helpers.GenerateTokenErr), http.StatusInternalServerError)
+		return
+	}
+
+	session, err := h.sessionsStore.RotateRefreshToken(ctx, utoken.HashRefreshToken(refreshToken), newHash, now.Add(h.refreshExpPeriod), now)
+	if err != nil {
+		switch {
+		case errors.Is(err, sessions.ErrTokenReused):
+			log.Ctx(ctx).Warn().Msg("Refresh token reused, session revoked")
+			fallthrough
+		case errors.Is(err, sessions.ErrTokenNotFound), errors.Is(err, sessions.ErrSessionEnded):
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid or expired refresh token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		default:
+			log.Ctx(ctx).Error().Err(err).Msg("Failed to rotate refresh token")
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to refresh token", helpers.InternalError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	accessToken, err := h.tokens.Issue(session.UserID, session.ID, now)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to generate JWT token for user: %s", session.UserID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to generate token", helpers.GenerateTokenErr), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteData(ctx, resp, h.tokenResponse(accessToken, newToken), http.StatusOK)
+}
+
+// Logout ends the session of the refresh token. Its access tokens are rejected from now on.
+func (h *Handler) Logout(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	refreshToken, ok := decodeRefreshToken(resp, req)
+	if !ok {
+		return
+	}
+
+	if err := h.sessionsStore.RevokeSession(ctx, utoken.HashRefreshToken(refreshToken), time.Now()); err != nil {
+		if errors.Is(err, sessions.ErrTokenNotFound) {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid refresh token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msg("Failed to revoke session")
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to log out", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll ends every session of the calling user, on all devices.
+func (h *Handler) LogoutAll(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	userID, ok := helpers.UserIDFromContext(ctx)
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.sessionsStore.RevokeUserSessions(ctx, userID, time.Now()); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to revoke sessions of user %s", userID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to log out", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// startSession logs the user in on a new session and returns its first tokens.
+func (h *Handler) startSession(ctx context.Context, userID string, now time.Time) (*TokenResponse, error) {
+	refreshToken, refreshHash, err := utoken.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := h.sessionsStore.CreateSession(ctx, userID, refreshHash, now.Add(h.refreshExpPeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := h.tokens.Issue(userID, session.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.tokenResponse(accessToken, refreshToken), nil
+}
+
+func (h *Handler) tokenResponse(accessToken, refreshToken string) *TokenResponse {
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(h.tokens.TTL().Seconds()),
+	}
+}
+
+// decodeRefreshToken reads the refresh token of the request body. Error responses are written here.
+func decodeRefreshToken(resp http.ResponseWriter, req *http.Request) (string, bool) {
+	var refreshReq RefreshTokenRequest
+	if err := json.NewDecoder(req.Body).Decode(&refreshReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return "", false
+	}
+	if refreshReq.RefreshToken == "" {
+		var errs helpers.FieldErrors
+		errs.Add("refresh_token", "refresh_token is required")
+		helpers.WriteFieldErrors(resp, errs)
+		return "", false
+	}
+	return refreshReq.RefreshToken, true
+}
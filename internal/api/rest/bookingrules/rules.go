@@ -0,0 +1,227 @@
+package bookingrules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/schedules"
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/business_accounts"
+	"booking-service/internal/store/services"
+	"booking-service/internal/store/specialists"
+)
+
+// Rules is what a new booking is validated against.
+type Rules struct {
+	// Service combines the booked services into one appointment.
+	Service  *services.Service
+	Items    []bookings.BookingItem
+	Calendar *schedules.Calendar
+}
+
+// Loader loads the rules of bookings from the services, the business and its specialists.
+type Loader struct {
+	servicesStore         services.Store
+	businessAccountsStore business_accounts.Store
+	specialistsStore      specialists.Store
+}
+
+func NewLoader(servicesStore services.Store, businessAccountsStore business_accounts.Store, specialistsStore specialists.Store) *Loader {
+	return &Loader{
+		servicesStore:         servicesStore,
+		businessAccountsStore: businessAccountsStore,
+		specialistsStore:      specialistsStore,
+	}
+}
+
+// Load loads the services to book, in order, with the chosen variants and add-ons applied, and the
+// calendar of the business offering them. With a specialist, who must perform all of the services, the calendar
+// is narrowed to their working time.
+func (l *Loader) Load(ctx context.Context, businessID string, specialistID *string,
+	serviceIDs, variantIDs, addOnIDs []string) (*Rules, helpers.FieldErrors, error) {
+	var errs helpers.FieldErrors
+
+	field := "service_id"
+	if len(serviceIDs) > 1 {
+		field = "service_ids"
+	}
+
+	booked := make([]*services.Service, 0, len(serviceIDs))
+	for _, serviceID := range serviceIDs {
+		service, err := l.servicesStore.GetService(ctx, serviceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case service == nil:
+			errs.Add(field, fmt.Sprintf("service %s not found", serviceID))
+		case service.BusinessAccountID != businessID:
+			errs.Add(field, fmt.Sprintf("service %s is not offered by this business", serviceID))
+		case !service.IsActive:
+			errs.Add(field, fmt.Sprintf("service %s is not active", serviceID))
+		case len(serviceIDs) > 1 && service.IsGroupClass():
+			errs.Add(field, fmt.Sprintf("service %s is a group class and cannot be combined with other services", serviceID))
+		case len(booked) > 0 && service.Price.Currency != booked[0].Price.Currency:
+			errs.Add(field, "services booked together must share one currency")
+		default:
+			booked = append(booked, service)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs, nil
+	}
+
+	var options []*services.Option
+	if len(variantIDs) > 0 || len(addOnIDs) > 0 {
+		var err error
+		if options, err = l.servicesStore.ListOptions(ctx, serviceIDs); err != nil {
+			return nil, nil, err
+		}
+	}
+	selections, err := services.Select(booked, options, variantIDs, addOnIDs)
+	if err != nil {
+		var optionErr *services.OptionError
+		if errors.As(err, &optionErr) {
+			errs.Add(optionErr.Field, optionErr.Message)
+			return nil, errs, nil
+		}
+		return nil, nil, err
+	}
+
+	performed := make([]*services.Service, 0, len(selections))
+	items := make([]bookings.BookingItem, 0, len(selections))
+	for _, selection := range selections {
+		service := selection.Performed()
+		performed = append(performed, service)
+		item := bookings.BookingItem{
+			ServiceID:       service.ID,
+			DurationMinutes: service.DurationMinutes,
+			Price:           service.Price,
+		}
+		if selection.Variant != nil {
+			item.VariantID = &selection.Variant.ID
+		}
+		for _, addOn := range selection.AddOns {
+			item.AddOnIDs = append(item.AddOnIDs, addOn.ID)
+		}
+		items = append(items, item)
+	}
+
+	hours, err := l.businessAccountsStore.GetWorkingHours(ctx, businessID)
+	if err != nil {
+		if errors.Is(err, business_accounts.NotFoundError) {
+			errs.Add("business_id", "business not found")
+			return nil, errs, nil
+		}
+		return nil, nil, err
+	}
+
+	calendar, err := schedules.NewCalendar(hours)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if specialistID != nil {
+		staff, errs, err := l.StaffHours(ctx, *specialistID, businessID, serviceIDs)
+		if err != nil || len(errs) > 0 {
+			return nil, errs, err
+		}
+		calendar = calendar.WithStaff(staff)
+	}
+
+	return &Rules{Service: services.Combine(performed), Items: items, Calendar: calendar}, nil, nil
+}
+
+// LoadForBooking loads the rules a booking is moved against: those of a new booking of its services, with
+// the options chosen when it was booked, by its specialist. The appointment keeps the duration it was booked with.
+func (l *Loader) LoadForBooking(ctx context.Context, booking *bookings.Booking) (*Rules, helpers.FieldErrors, error) {
+	serviceIDs := []string{booking.ServiceID}
+	var variantIDs, addOnIDs []string
+	if len(booking.Items) > 0 {
+		serviceIDs = make([]string, 0, len(booking.Items))
+		for _, item := range booking.Items {
+			serviceIDs = append(serviceIDs, item.ServiceID)
+			if item.VariantID != nil {
+				variantIDs = append(variantIDs, *item.VariantID)
+			}
+			addOnIDs = append(addOnIDs, item.AddOnIDs...)
+		}
+	}
+
+	rules, errs, err := l.Load(ctx, booking.BusinessID, booking.SpecialistID, serviceIDs, variantIDs, addOnIDs)
+	if err != nil || len(errs) > 0 {
+		return nil, errs, err
+	}
+	rules.Service.DurationMinutes = int(booking.EndTime.Sub(booking.StartTime) / time.Minute)
+	return rules, nil, nil
+}
+
+// StaffHours checks that the specialist works for the business and performs the services, and returns
+// their upcoming working time.
+func (l *Loader) StaffHours(ctx context.Context, specialistID, businessID string, serviceIDs []string) (*schedules.StaffHours, helpers.FieldErrors, error) {
+	var errs helpers.FieldErrors
+
+	specialist, err := l.specialistsStore.GetSpecialist(ctx, specialistID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if specialist == nil || specialist.BusinessAccountID != businessID {
+		errs.Add("specialist_id", "specialist not found in this business")
+		return nil, errs, nil
+	}
+
+	for _, serviceID := range serviceIDs {
+		performs, err := l.specialistsStore.CanPerform(ctx, specialistID, serviceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !performs {
+			errs.Add("specialist_id", fmt.Sprintf("the specialist does not perform service %s", serviceID))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs, nil
+	}
+
+	schedule, err := l.specialistsStore.GetSchedule(ctx, specialistID, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	staff, err := schedules.NewStaffHours(schedule)
+	return staff, nil, err
+}
+
+// CheckSlot validates the time of a booking against the service rules and the calendar, and sets its end time.
+func CheckSlot(service *services.Service, calendar *schedules.Calendar, createReq *bookings.CreateBookingRequest, now time.Time) helpers.FieldErrors {
+	var errs helpers.FieldErrors
+
+	if createReq.StartTime.Before(service.EarliestStart(now)) {
+		errs.Add("start_time", fmt.Sprintf("must be booked at least %d minutes in advance", service.MinLeadMinutes))
+	}
+	if latest := service.LatestStart(now); !latest.IsZero() && createReq.StartTime.After(latest) {
+		errs.Add("start_time", fmt.Sprintf("must be booked at most %d days in advance", service.MaxHorizonDays))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	endTime := createReq.StartTime.Add(time.Duration(service.DurationMinutes) * time.Minute)
+	if !createReq.EndTime.IsZero() && !createReq.EndTime.Equal(endTime) {
+		errs.Add("end_time", fmt.Sprintf("must be %d minutes after start_time, the duration of the service", service.DurationMinutes))
+		return errs
+	}
+	createReq.EndTime = endTime
+
+	if !calendar.Contains(createReq.StartTime, createReq.EndTime) {
+		if calendar.Staff != nil {
+			errs.Add("start_time", "the specialist is not working for the whole appointment")
+		} else {
+			errs.Add("start_time", "the business is not open for the whole appointment")
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,115 @@
+package bookings
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"booking-service/internal/api/rest/bookingrules"
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/assignment"
+	"booking-service/internal/schedules"
+	"booking-service/internal/store/bookings"
+)
+
+// rankSpecialists returns the specialists a booking naming none may be assigned to, in order of preference of
+// the business' assignment strategy: those performing all of the booked services who work and are free for the
+// whole appointment. Businesses without specialists for the services keep taking unassigned bookings, as do
+// group classes, whose sessions are chosen by the customer.
+func (h *Handler) rankSpecialists(ctx context.Context, createReq *bookings.CreateBookingRequest, rules *bookingrules.Rules,
+	serviceIDs []string) ([]string, helpers.FieldErrors, error) {
+	if rules.Service.IsGroupClass() {
+		return nil, nil, nil
+	}
+
+	qualified, err := h.specialistsStore.ListQualified(ctx, createReq.BusinessID, serviceIDs)
+	if err != nil || len(qualified) == 0 {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	blockedStart := createReq.StartTime.Add(-rules.Service.BufferBefore())
+	blockedEnd := createReq.EndTime.Add(rules.Service.BufferAfter())
+	var free []string
+	ratings := make(map[string]*float64, len(qualified))
+	for _, specialist := range qualified {
+		ratings[specialist.ID] = specialist.Rating
+		schedule, err := h.specialistsStore.GetSchedule(ctx, specialist.ID, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		staff, err := schedules.NewStaffHours(schedule)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !rules.Calendar.WithStaff(staff).Contains(createReq.StartTime, createReq.EndTime) {
+			continue
+		}
+
+		busy, err := h.store.ListBusyWindows(ctx, createReq.BusinessID, &specialist.ID, blockedStart, blockedEnd)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(busy) == 0 {
+			free = append(free, specialist.ID)
+		}
+	}
+	if len(free) == 0 {
+		var errs helpers.FieldErrors
+		errs.Add("start_time", "no specialist is available for the whole appointment")
+		return nil, errs, nil
+	}
+
+	policy, err := h.businessAccountsStore.GetBookingPolicy(ctx, createReq.BusinessID)
+	if err != nil {
+		return nil, nil, err
+	}
+	name := policy.AssignmentStrategy
+	strategy, ok := assignment.ByName(name)
+	if !ok {
+		name = assignment.RoundRobin
+		strategy, _ = assignment.ByName(name)
+	}
+
+	day := rules.Calendar.Date(createReq.StartTime.In(rules.Calendar.Location))
+	loads, err := h.store.ListSpecialistLoads(ctx, createReq.BusinessID, free, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	candidates := make([]assignment.Candidate, 0, len(free))
+	for _, specialistID := range free {
+		candidates = append(candidates, assignment.Candidate{
+			SpecialistID:    specialistID,
+			BookingsThatDay: loads[specialistID].Bookings,
+			LastAssignedAt:  loads[specialistID].LastAssignedAt,
+			Rating:          ratings[specialistID],
+		})
+	}
+
+	ranked := make([]string, 0, len(candidates))
+	for _, candidate := range strategy.Rank(candidates) {
+		ranked = append(ranked, candidate.SpecialistID)
+	}
+	createReq.AssignmentStrategy = &name
+
+	return ranked, nil, nil
+}
+
+// createBooking stores a validated booking. A booking assigned automatically goes to the first of the ranked
+// specialists who is still free, as concurrent bookings may take the time of the others.
+func (h *Handler) createBooking(ctx context.Context, createReq bookings.CreateBookingRequest, ranked []string) (*bookings.Booking, error) {
+	if len(ranked) == 0 {
+		return h.store.CreateBooking(ctx, createReq)
+	}
+
+	var err error
+	for _, specialistID := range ranked {
+		createReq.SpecialistID = &specialistID
+		var booking *bookings.Booking
+		if booking, err = h.store.CreateBooking(ctx, createReq); !errors.Is(err, bookings.ErrBookingOverlap) {
+			return booking, err
+		}
+	}
+	return nil, err
+}
@@ -1,103 +1,393 @@
 package bookings
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
+	"booking-service/internal/api/rest/bookingrules"
+	"booking-service/internal/api/rest/helpers"
 	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/business_accounts"
+	"booking-service/internal/store/specialists"
+	"booking-service/internal/waitlist"
 
 	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
 )
 
 type Handler struct {
-	store bookings.Store
+	store                 bookings.Store
+	businessAccountsStore business_accounts.Store
+	specialistsStore      specialists.Store
+	rules                 *bookingrules.Loader
+	offerer               *waitlist.Offerer
+	// holdTTL is how long a checkout hold blocks its slot.
+	holdTTL time.Duration
 }
 
-func NewHandler(store bookings.Store) *Handler {
+func NewHandler(store bookings.Store, businessAccountsStore business_accounts.Store, specialistsStore specialists.Store,
+	rules *bookingrules.Loader, offerer *waitlist.Offerer, holdTTL time.Duration) *Handler {
 	return &Handler{
-		store: store,
+		store:                 store,
+		businessAccountsStore: businessAccountsStore,
+		specialistsStore:      specialistsStore,
+		rules:                 rules,
+		offerer:               offerer,
+		holdTTL:               holdTTL,
 	}
 }
 
+// CreateBooking books an appointment for the calling user.
 func (h *Handler) CreateBooking(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	userID, ok := helpers.UserIDFromContext(ctx)
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return
+	}
+
 	var createReq bookings.CreateBookingRequest
 	if err := json.NewDecoder(req.Body).Decode(&createReq); err != nil {
-		http.Error(resp, "invalid request body", http.StatusBadRequest)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
 		return
 	}
+	createReq.UserID = userID
 
-	// Validate required fields
-	if createReq.UserID == "" {
-		http.Error(resp, "user_id is required", http.StatusBadRequest)
+	if errs := validateCreateBookingRequest(createReq); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
 		return
 	}
-	if createReq.BusinessID == "" {
-		http.Error(resp, "business_id is required", http.StatusBadRequest)
+
+	ranked, errs, err := h.checkBookingSlot(ctx, &createReq)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to validate booking of service %s", createReq.ServiceID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate booking", helpers.InternalError), http.StatusInternalServerError)
 		return
 	}
-	if createReq.ServiceID == "" {
-		http.Error(resp, "service_id is required", http.StatusBadRequest)
+	if len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
 		return
 	}
-	if createReq.StartTime.IsZero() {
-		http.Error(resp, "start_time is required", http.StatusBadRequest)
+
+	booking, err := h.createBooking(ctx, createReq, ranked)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to create booking")
 		return
 	}
-	if createReq.EndTime.IsZero() {
-		http.Error(resp, "end_time is required", http.StatusBadRequest)
+
+	helpers.WriteData(ctx, resp, booking, http.StatusCreated)
+}
+
+// checkBookingSlot validates the booking against the booked services and the working hours of the business,
+// narrowed to the working time of the specialist if the booking names one. Otherwise the specialists it may be
+// assigned to are returned, in order of preference.
+// The end time is derived from the service durations; a client-sent end time must match it. Service buffers
+// are enforced by the store together with the overlap check.
+func (h *Handler) checkBookingSlot(ctx context.Context, createReq *bookings.CreateBookingRequest) ([]string, helpers.FieldErrors, error) {
+	serviceIDs := bookedServiceIDs(*createReq)
+	rules, errs, err := h.rules.Load(ctx, createReq.BusinessID, createReq.SpecialistID, serviceIDs,
+		createReq.VariantIDs, createReq.AddOnIDs)
+	if err != nil || len(errs) > 0 {
+		return nil, errs, err
+	}
+	createReq.ServiceID = serviceIDs[0]
+	createReq.Items = rules.Items
+	if errs := bookingrules.CheckSlot(rules.Service, rules.Calendar, createReq, time.Now()); len(errs) > 0 {
+		return nil, errs, nil
+	}
+
+	if createReq.SpecialistID != nil {
+		return nil, nil, nil
+	}
+	return h.rankSpecialists(ctx, createReq, rules, serviceIDs)
+}
+
+// GetBooking returns a booking to its customer or to the owner of its business, who see the guest's contact
+// details too.
+func (h *Handler) GetBooking(resp http.ResponseWriter, req *http.Request) {
+	booking, _, ok := h.authorizeBooking(resp, req)
+	if !ok {
 		return
 	}
 
-	// Validate time range
-	if createReq.EndTime.Before(createReq.StartTime) {
-		http.Error(resp, "end_time must be after start_time", http.StatusBadRequest)
+	helpers.WriteData(req.Context(), resp, booking, http.StatusOK)
+}
+
+// ListMyBookings returns the bookings of the calling user, page by page.
+func (h *Handler) ListMyBookings(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
 		return
 	}
 
-	// Validate booking is not in the past
-	if createReq.StartTime.Before(time.Now()) {
-		http.Error(resp, "cannot create booking in the past", http.StatusBadRequest)
+	listReq, err := parseListBookingsQueries(req.URL.Query())
+	if err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.InvalidQueries), http.StatusBadRequest)
 		return
 	}
+	listReq.UserID = userID
 
-	booking, err := h.store.CreateBooking(req.Context(), createReq)
+	result, err := h.store.ListBookings(ctx, *listReq)
 	if err != nil {
-		http.Error(resp, "failed to create booking", http.StatusInternalServerError)
+		if errors.Is(err, bookings.ErrInvalidCursor) {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid cursor", helpers.InvalidQueries), http.StatusBadRequest)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to list bookings of user %s", userID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to list bookings", helpers.InternalError), http.StatusInternalServerError)
 		return
 	}
 
-	resp.Header().Set("Content-Type", "application/json")
-	resp.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(resp).Encode(booking); err != nil {
-		http.Error(resp, "failed to encode response", http.StatusInternalServerError)
+	helpers.WriteData(ctx, resp, result, http.StatusOK)
+}
+
+func (h *Handler) ConfirmBooking(resp http.ResponseWriter, req *http.Request) {
+	h.transitionBooking(resp, req, bookings.StatusConfirmed)
+}
+
+func (h *Handler) CompleteBooking(resp http.ResponseWriter, req *http.Request) {
+	h.transitionBooking(resp, req, bookings.StatusCompleted)
+}
+
+func (h *Handler) NoShowBooking(resp http.ResponseWriter, req *http.Request) {
+	h.transitionBooking(resp, req, bookings.StatusNoShow)
+}
+
+// CancelBooking cancels a booking on behalf of the business that owns it, or of the customer who made it.
+// Customers are bound to the cancellation cutoff of the business.
+func (h *Handler) CancelBooking(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	scope, err := parseSeriesScope(req.URL.Query())
+	if err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.InvalidQueries), http.StatusBadRequest)
+		return
+	}
+
+	booking, actor, ok := h.authorizeBooking(resp, req)
+	if !ok {
+		return
+	}
+
+	to := bookings.StatusCancelledByBusiness
+	if !actor.Business {
+		to = bookings.StatusCancelledByCustomer
+	}
+
+	if scope != bookings.ScopeThis {
+		h.cancelSeries(resp, req, booking, actor, scope, to)
+		return
+	}
+
+	if !actor.Business && !h.checkCancellationCutoff(resp, req, booking) {
 		return
 	}
+
+	booking, err = h.store.TransitionBooking(ctx, booking.ID, to, actor.UserID)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to cancel booking")
+		return
+	}
+	h.offerFreedSlots(ctx, booking)
+
+	helpers.WriteData(ctx, resp, booking, http.StatusOK)
 }
 
-func (h *Handler) GetBooking(resp http.ResponseWriter, req *http.Request) {
-	vars := mux.Vars(req)
-	bookingID := vars["id"]
+// RescheduleBooking moves a booking to a new time window, with the same actor rules as CancelBooking. The new
+// time is validated like a new booking; the end time follows from the duration of the booking.
+// For a series scope, the other occurrences are moved by the same change of date and time of day.
+func (h *Handler) RescheduleBooking(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var rescheduleReq bookings.RescheduleBookingRequest
+	if err := json.NewDecoder(req.Body).Decode(&rescheduleReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
 
-	if bookingID == "" {
-		http.Error(resp, "booking ID is required", http.StatusBadRequest)
+	if err := validateRescheduleBookingRequest(rescheduleReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.ValidationError), http.StatusBadRequest)
 		return
 	}
 
-	booking, err := h.store.GetBooking(req.Context(), bookingID)
+	scope, err := parseSeriesScope(req.URL.Query())
 	if err != nil {
-		http.Error(resp, "failed to get booking", http.StatusInternalServerError)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.InvalidQueries), http.StatusBadRequest)
 		return
 	}
 
-	if booking == nil {
-		http.Error(resp, "booking not found", http.StatusNotFound)
+	booking, actor, ok := h.authorizeBooking(resp, req)
+	if !ok {
+		return
+	}
+
+	if scope != bookings.ScopeThis {
+		h.rescheduleSeries(resp, req, booking, actor, scope, rescheduleReq)
+		return
+	}
+
+	if !actor.Business && !h.checkCancellationCutoff(resp, req, booking) {
 		return
 	}
 
-	resp.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(resp).Encode(booking); err != nil {
-		http.Error(resp, "failed to encode response", http.StatusInternalServerError)
+	rules, errs, err := h.rules.LoadForBooking(ctx, booking)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to validate reschedule of booking %s", booking.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate booking", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	if len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+	moved := bookings.CreateBookingRequest{StartTime: rescheduleReq.StartTime, EndTime: rescheduleReq.EndTime}
+	if errs := bookingrules.CheckSlot(rules.Service, rules.Calendar, &moved, time.Now()); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
 		return
 	}
+	rescheduleReq.EndTime = moved.EndTime
+
+	freed := booking
+	booking, err = h.store.RescheduleBooking(ctx, booking.ID, rescheduleReq, actor.UserID)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to reschedule booking")
+		return
+	}
+	h.offerFreedSlots(ctx, freed)
+
+	helpers.WriteData(ctx, resp, booking, http.StatusOK)
+}
+
+// transitionBooking moves the booking to the given status on behalf of the business that owns it.
+func (h *Handler) transitionBooking(resp http.ResponseWriter, req *http.Request, to bookings.Status) {
+	ctx := req.Context()
+
+	booking, actor, ok := h.authorizeBooking(resp, req)
+	if !ok {
+		return
+	}
+
+	if !actor.Business {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Forbidden: you do not own this business account", helpers.Forbidden), http.StatusForbidden)
+		return
+	}
+
+	booking, err := h.store.TransitionBooking(ctx, booking.ID, to, actor.UserID)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to update booking")
+		return
+	}
+
+	helpers.WriteData(ctx, resp, booking, http.StatusOK)
+}
+
+// bookingActor is the caller changing a booking: either the business owner or the customer of the booking.
+type bookingActor struct {
+	UserID   string
+	Business bool
+}
+
+// authorizeBooking loads the booking from the path and resolves the caller's role for it. The business owner
+// takes precedence, so owners booking their own business act as the business. Error responses are written here.
+func (h *Handler) authorizeBooking(resp http.ResponseWriter, req *http.Request) (*bookings.Booking, *bookingActor, bool) {
+	ctx := req.Context()
+	bookingID := mux.Vars(req)["id"]
+
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return nil, nil, false
+	}
+
+	booking, err := h.store.GetBooking(ctx, bookingID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get booking %s", bookingID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get booking", helpers.InternalError), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if booking == nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Booking not found", helpers.NotFound), http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	owns, err := h.businessAccountsStore.UserOwnsBusinessAccount(ctx, booking.BusinessID, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to validate ownership of business account %s", booking.BusinessID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate ownership", helpers.InternalError), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if owns {
+		return booking, &bookingActor{UserID: userID, Business: true}, true
+	}
+
+	if booking.UserID == nil || *booking.UserID != userID {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Forbidden: this is not your booking", helpers.Forbidden), http.StatusForbidden)
+		return nil, nil, false
+	}
+
+	return booking, &bookingActor{UserID: userID}, true
+}
+
+// checkCancellationCutoff rejects customer changes made later than the business allows before the start.
+func (h *Handler) checkCancellationCutoff(resp http.ResponseWriter, req *http.Request, booking *bookings.Booking) bool {
+	ctx := req.Context()
+
+	policy, err := h.businessAccountsStore.GetBookingPolicy(ctx, booking.BusinessID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get booking policy of business account %s", booking.BusinessID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get booking policy", helpers.InternalError), http.StatusInternalServerError)
+		return false
+	}
+
+	cutoff := policy.CancellationCutoff()
+	if time.Until(booking.StartTime) < cutoff {
+		helpers.WriteErrorResponse(
+			resp,
+			helpers.NewErrorResponse(fmt.Sprintf("bookings can only be changed at least %s before the start", cutoff), helpers.Forbidden),
+			http.StatusForbidden,
+		)
+		return false
+	}
+
+	return true
+}
+
+// offerFreedSlots passes the slots of cancelled bookings, or the old slots of rescheduled ones, to the waitlist.
+// The change itself already succeeded, so failures are only logged.
+func (h *Handler) offerFreedSlots(ctx context.Context, freed ...*bookings.Booking) {
+	for _, booking := range freed {
+		if err := h.offerer.OfferSlot(ctx, booking); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to offer the slot of booking %s to the waitlist", booking.ID)
+		}
+	}
+}
+
+func writeStoreError(resp http.ResponseWriter, req *http.Request, err error, message string) {
+	var overlapErr *bookings.OverlapError
+	switch {
+	case errors.Is(err, bookings.ErrBookingNotFound):
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Booking not found", helpers.NotFound), http.StatusNotFound)
+	case errors.Is(err, bookings.ErrServiceNotFound):
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Service not found", helpers.NotFound), http.StatusNotFound)
+	case errors.Is(err, bookings.ErrInvalidTransition), errors.Is(err, bookings.ErrSessionFull),
+		errors.Is(err, bookings.ErrAlreadyAttending):
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.Conflict), http.StatusConflict)
+	case errors.As(err, &overlapErr):
+		helpers.WriteErrorResponse(
+			resp,
+			helpers.NewErrorResponse(overlapErr.Error(), helpers.Conflict).WithDetails(overlapErr.Conflicting),
+			http.StatusConflict,
+		)
+	default:
+		log.Ctx(req.Context()).Error().Err(err).Msg(message)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(message, helpers.InternalError), http.StatusInternalServerError)
+	}
 }
@@ -0,0 +1,202 @@
+package bookings
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/business_accounts"
+	waitliststore "booking-service/internal/store/waitlist"
+	"booking-service/internal/waitlist"
+
+	"github.com/gorilla/mux"
+)
+
+type fakeBookings struct {
+	bookings.Store
+	bookings map[string]*bookings.Booking
+}
+
+func (f *fakeBookings) GetBooking(_ context.Context, id string) (*bookings.Booking, error) {
+	return f.bookings[id], nil
+}
+
+func (f *fakeBookings) TransitionBooking(_ context.Context, id string, to bookings.Status, _ string) (*bookings.Booking, error) {
+	booking := f.bookings[id]
+	if booking == nil {
+		return nil, bookings.ErrBookingNotFound
+	}
+	booking.Status = to
+	return booking, nil
+}
+
+type fakeBusinessAccounts struct {
+	business_accounts.Store
+	owner  string
+	policy business_accounts.BookingPolicy
+}
+
+func (f *fakeBusinessAccounts) UserOwnsBusinessAccount(_ context.Context, _, userID string) (bool, error) {
+	return userID == f.owner, nil
+}
+
+func (f *fakeBusinessAccounts) GetBookingPolicy(context.Context, string) (*business_accounts.BookingPolicy, error) {
+	return &f.policy, nil
+}
+
+// emptyWaitlist has nobody waiting for the slots of cancelled bookings.
+type emptyWaitlist struct {
+	waitliststore.Store
+}
+
+func (emptyWaitlist) NextEntry(context.Context, waitliststore.FreedSlot) (*waitliststore.Entry, error) {
+	return nil, nil
+}
+
+// newTestHandler returns a handler with one pending booking of the customer at the business of the owner, which
+// lets customers change bookings up to a day before their start.
+func newTestHandler(start time.Time) (*Handler, *bookings.Booking) {
+	customer := "customer"
+	booking := &bookings.Booking{ID: "booking", UserID: &customer, BusinessID: "salon", Status: bookings.StatusPending,
+		StartTime: start, EndTime: start.Add(time.Hour), Guest: &bookings.Guest{Name: "Jane Doe"}}
+	store := &fakeBookings{bookings: map[string]*bookings.Booking{booking.ID: booking}}
+	return &Handler{
+		store:                 store,
+		businessAccountsStore: &fakeBusinessAccounts{owner: "owner", policy: business_accounts.BookingPolicy{CancellationCutoffMinutes: 24 * 60}},
+		offerer:               waitlist.NewOfferer(store, emptyWaitlist{}, time.Minute),
+	}, booking
+}
+
+// requestAs returns a request for the booking on behalf of the user.
+func requestAs(userID, bookingID string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	return mux.SetURLVars(req.WithContext(helpers.WithUserID(req.Context(), userID)), map[string]string{"id": bookingID})
+}
+
+// serveAs calls the handler for the booking on behalf of the user.
+func serveAs(handler http.HandlerFunc, userID, bookingID string) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	handler(resp, requestAs(userID, bookingID))
+	return resp
+}
+
+func TestHandler_GetBooking(t *testing.T) {
+	tests := []struct {
+		name      string
+		userID    string
+		bookingID string
+		want      int
+	}{
+		{name: "customer", userID: "customer", bookingID: "booking", want: http.StatusOK},
+		{name: "business owner", userID: "owner", bookingID: "booking", want: http.StatusOK},
+		{name: "another user", userID: "stranger", bookingID: "booking", want: http.StatusForbidden},
+		{name: "unknown booking", userID: "customer", bookingID: "unknown", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := newTestHandler(time.Now().Add(48 * time.Hour))
+
+			if resp := serveAs(h.GetBooking, tt.userID, tt.bookingID); resp.Code != tt.want {
+				t.Errorf("status = %d, want %d", resp.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_authorizeBooking(t *testing.T) {
+	tests := []struct {
+		name         string
+		userID       string
+		wantStatus   int
+		wantBusiness bool
+	}{
+		{name: "business owner", userID: "owner", wantBusiness: true},
+		{name: "customer", userID: "customer"},
+		{name: "another user", userID: "stranger", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, booking := newTestHandler(time.Now().Add(48 * time.Hour))
+			resp := httptest.NewRecorder()
+
+			got, actor, ok := h.authorizeBooking(resp, requestAs(tt.userID, booking.ID))
+
+			if tt.wantStatus != 0 {
+				if ok || resp.Code != tt.wantStatus {
+					t.Fatalf("authorizeBooking() ok = %v, status = %d, want failure with %d", ok, resp.Code, tt.wantStatus)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("authorizeBooking() failed with status %d", resp.Code)
+			}
+			if got != booking || actor.UserID != tt.userID || actor.Business != tt.wantBusiness {
+				t.Errorf("authorizeBooking() = %s, %+v, want %s acting as business %v", got.ID, actor, booking.ID, tt.wantBusiness)
+			}
+		})
+	}
+}
+
+func TestHandler_checkCancellationCutoff(t *testing.T) {
+	tests := []struct {
+		name   string
+		starts time.Duration
+		want   bool
+	}{
+		{name: "outside the cutoff", starts: 25 * time.Hour, want: true},
+		{name: "inside the cutoff", starts: 23 * time.Hour},
+		{name: "started already", starts: -time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, booking := newTestHandler(time.Now().Add(tt.starts))
+			resp := httptest.NewRecorder()
+
+			if got := h.checkCancellationCutoff(resp, requestAs("customer", booking.ID), booking); got != tt.want {
+				t.Fatalf("checkCancellationCutoff() = %v, want %v", got, tt.want)
+			}
+			if !tt.want && resp.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", resp.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestHandler_CancelBooking(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		starts     time.Duration
+		want       int
+		wantStatus bookings.Status
+	}{
+		{name: "customer outside the cutoff", userID: "customer", starts: 48 * time.Hour, want: http.StatusOK,
+			wantStatus: bookings.StatusCancelledByCustomer},
+		{name: "customer inside the cutoff", userID: "customer", starts: 2 * time.Hour, want: http.StatusForbidden,
+			wantStatus: bookings.StatusPending},
+		{name: "business owner inside the cutoff", userID: "owner", starts: 2 * time.Hour, want: http.StatusOK,
+			wantStatus: bookings.StatusCancelledByBusiness},
+		{name: "another user", userID: "stranger", starts: 48 * time.Hour, want: http.StatusForbidden,
+			wantStatus: bookings.StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, booking := newTestHandler(time.Now().Add(tt.starts))
+
+			if resp := serveAs(h.CancelBooking, tt.userID, booking.ID); resp.Code != tt.want {
+				t.Fatalf("status = %d, want %d", resp.Code, tt.want)
+			}
+			if booking.Status != tt.wantStatus {
+				t.Errorf("booking status = %s, want %s", booking.Status, tt.wantStatus)
+			}
+		})
+	}
+}
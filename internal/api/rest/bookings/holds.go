@@ -0,0 +1,141 @@
+package bookings
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/waitlist"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// CreateHold reserves a slot for the calling user while they check out. The hold blocks the slot like a
+// booking until it is converted, released, or expires after the hold TTL.
+func (h *Handler) CreateHold(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return
+	}
+
+	var createReq bookings.CreateBookingRequest
+	if err := json.NewDecoder(req.Body).Decode(&createReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+	createReq.UserID = userID
+
+	if errs := validateCreateBookingRequest(createReq); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	ranked, errs, err := h.checkBookingSlot(ctx, &createReq)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to validate hold of service %s", createReq.ServiceID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate booking", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	if len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	expiresAt := time.Now().Add(h.holdTTL)
+	createReq.HoldExpiresAt = &expiresAt
+
+	hold, err := h.createBooking(ctx, createReq, ranked)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to hold slot")
+		return
+	}
+
+	helpers.WriteData(ctx, resp, hold, http.StatusCreated)
+}
+
+// ConvertHold turns a live hold of the calling user into a pending booking.
+func (h *Handler) ConvertHold(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	hold, userID, ok := h.authorizeHold(resp, req)
+	if !ok {
+		return
+	}
+
+	if hold.HoldExpiresAt == nil || !hold.HoldExpiresAt.After(time.Now()) {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("The hold has expired", helpers.Conflict), http.StatusConflict)
+		return
+	}
+
+	booking, err := h.store.TransitionBooking(ctx, hold.ID, bookings.StatusPending, userID)
+	if err != nil {
+		if errors.Is(err, bookings.ErrInvalidTransition) {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("The hold has expired", helpers.Conflict), http.StatusConflict)
+			return
+		}
+		writeStoreError(resp, req, err, "Failed to convert hold")
+		return
+	}
+
+	helpers.WriteData(ctx, resp, booking, http.StatusOK)
+}
+
+// ReleaseHold gives up a hold of the calling user before it expires.
+func (h *Handler) ReleaseHold(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	hold, userID, ok := h.authorizeHold(resp, req)
+	if !ok {
+		return
+	}
+
+	err := h.offerer.ReleaseHold(ctx, hold, userID, waitlist.StatusCancelled)
+	if errors.Is(err, bookings.ErrInvalidTransition) {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("The booking is not held anymore", helpers.Conflict), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to release hold %s", hold.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to release hold", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeHold loads the hold from the path and makes sure it is a hold of the caller. Holds of other users
+// are reported as not found. Error responses are written here.
+func (h *Handler) authorizeHold(resp http.ResponseWriter, req *http.Request) (*bookings.Booking, string, bool) {
+	ctx := req.Context()
+	holdID := mux.Vars(req)["id"]
+
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return nil, "", false
+	}
+
+	hold, err := h.store.GetBooking(ctx, holdID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get hold %s", holdID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get hold", helpers.InternalError), http.StatusInternalServerError)
+		return nil, "", false
+	}
+	if hold == nil || hold.UserID == nil || *hold.UserID != userID {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Hold not found", helpers.NotFound), http.StatusNotFound)
+		return nil, "", false
+	}
+	if hold.Status != bookings.StatusHeld {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("The booking is not held anymore", helpers.Conflict), http.StatusConflict)
+		return nil, "", false
+	}
+
+	return hold, userID, true
+}
@@ -20,5 +20,29 @@ func (r Router) RegisterRoutes(router *mux.Router) {
 	bookingRouter.Use(r.authMiddleware.Middleware)
 
 	bookingRouter.HandleFunc("/", r.handler.CreateBooking).Methods(http.MethodPost)
+
+	// Recurring bookings; single occurrences are changed through the booking endpoints with a scope parameter
+	bookingRouter.HandleFunc("/series", r.handler.CreateBookingSeries).Methods(http.MethodPost)
+	bookingRouter.HandleFunc("/series/{id}", r.handler.GetBookingSeries).Methods(http.MethodGet)
+
+	// Checkout holds of the calling user, expired by the hold sweeper unless converted
+	bookingRouter.HandleFunc("/holds", r.handler.CreateHold).Methods(http.MethodPost)
+	bookingRouter.HandleFunc("/holds/{id}/convert", r.handler.ConvertHold).Methods(http.MethodPost)
+	bookingRouter.HandleFunc("/holds/{id}", r.handler.ReleaseHold).Methods(http.MethodDelete)
+
 	bookingRouter.HandleFunc("/{id}", r.handler.GetBooking).Methods(http.MethodGet)
+
+	// Booking lifecycle, driven by the business that owns the booking
+	bookingRouter.HandleFunc("/{id}/confirm", r.handler.ConfirmBooking).Methods(http.MethodPost)
+	bookingRouter.HandleFunc("/{id}/complete", r.handler.CompleteBooking).Methods(http.MethodPost)
+	bookingRouter.HandleFunc("/{id}/no-show", r.handler.NoShowBooking).Methods(http.MethodPost)
+
+	// Available to both the business and the customer; customers are bound to the cancellation cutoff
+	bookingRouter.HandleFunc("/{id}/cancel", r.handler.CancelBooking).Methods(http.MethodPost)
+	bookingRouter.HandleFunc("/{id}/reschedule", r.handler.RescheduleBooking).Methods(http.MethodPut)
+
+	myBookingsRouter := router.PathPrefix("/my-bookings").Subrouter()
+	myBookingsRouter.Use(r.authMiddleware.Middleware)
+
+	myBookingsRouter.HandleFunc("", r.handler.ListMyBookings).Methods(http.MethodGet)
 }
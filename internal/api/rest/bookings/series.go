@@ -0,0 +1,278 @@
+package bookings
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"booking-service/internal/api/rest/bookingrules"
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/schedules"
+	"booking-service/internal/store/bookings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+type GetBookingSeriesResponse struct {
+	Series   *bookings.Series    `json:"series"`
+	Bookings []*bookings.Booking `json:"bookings"`
+}
+
+// SeriesChangeResponse lists the bookings changed by a cancel or reschedule of several occurrences.
+type SeriesChangeResponse struct {
+	Bookings []*bookings.Booking `json:"bookings"`
+}
+
+// CreateBookingSeries books a recurring appointment for the calling user. Every occurrence is validated like
+// a single booking; the ones that fail are reported as conflicts while the rest are booked.
+func (h *Handler) CreateBookingSeries(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return
+	}
+
+	var seriesReq bookings.CreateSeriesRequest
+	if err := json.NewDecoder(req.Body).Decode(&seriesReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+
+	if errs := validateCreateSeriesRequest(seriesReq); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+	seriesReq.UserID = userID
+	if seriesReq.Recurrence.Interval == 0 {
+		seriesReq.Recurrence.Interval = 1
+	}
+
+	rules, errs, err := h.rules.Load(ctx, seriesReq.BusinessID, seriesReq.SpecialistID, []string{seriesReq.ServiceID}, nil, nil)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to validate booking series of service %s", seriesReq.ServiceID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate booking", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	if len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	now := time.Now()
+	duration := time.Duration(rules.Service.DurationMinutes) * time.Minute
+	var conflicts []bookings.OccurrenceConflict
+	for _, start := range schedules.Occurrences(seriesReq.StartTime, recurrence(seriesReq.Recurrence), rules.Calendar.Location, maxSeriesOccurrences) {
+		occurrence := bookings.CreateBookingRequest{StartTime: start}
+		if errs := bookingrules.CheckSlot(rules.Service, rules.Calendar, &occurrence, now); len(errs) > 0 {
+			conflicts = append(conflicts, bookings.OccurrenceConflict{
+				TimeWindow: bookings.TimeWindow{StartTime: start, EndTime: start.Add(duration)},
+				Reason:     errs.Error(),
+			})
+			continue
+		}
+		seriesReq.Occurrences = append(seriesReq.Occurrences, bookings.TimeWindow{StartTime: occurrence.StartTime, EndTime: occurrence.EndTime})
+	}
+
+	var result *bookings.SeriesResult
+	if len(seriesReq.Occurrences) > 0 {
+		result, err = h.store.CreateSeries(ctx, seriesReq)
+		if err != nil {
+			writeStoreError(resp, req, err, "Failed to create booking series")
+			return
+		}
+		conflicts = append(conflicts, result.Conflicts...)
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].StartTime.Before(conflicts[j].StartTime) })
+
+	if result == nil || result.Series == nil {
+		helpers.WriteErrorResponse(
+			resp,
+			helpers.NewErrorResponse("No occurrence of the series can be booked", helpers.Conflict).WithDetails(conflicts),
+			http.StatusConflict,
+		)
+		return
+	}
+
+	result.Conflicts = conflicts
+	helpers.WriteData(ctx, resp, result, http.StatusCreated)
+}
+
+// recurrence converts the recurrence of a series to the one the schedules engine expands.
+func recurrence(rec bookings.Recurrence) schedules.Recurrence {
+	periods := map[bookings.Frequency]schedules.Period{
+		bookings.FrequencyDaily:   schedules.Daily,
+		bookings.FrequencyWeekly:  schedules.Weekly,
+		bookings.FrequencyMonthly: schedules.Monthly,
+	}
+	return schedules.Recurrence{Period: periods[rec.Frequency], Interval: rec.Interval, Count: rec.Count, Until: rec.Until}
+}
+
+// GetBookingSeries returns a series with all of its bookings, to its customer and to the business owner.
+func (h *Handler) GetBookingSeries(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	seriesID := mux.Vars(req)["id"]
+
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return
+	}
+
+	series, err := h.store.GetSeries(ctx, seriesID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get booking series %s", seriesID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get booking series", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	if series == nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Booking series not found", helpers.NotFound), http.StatusNotFound)
+		return
+	}
+
+	if series.UserID != userID {
+		owns, err := h.businessAccountsStore.UserOwnsBusinessAccount(ctx, series.BusinessID, userID)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to validate ownership of business account %s", series.BusinessID)
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate ownership", helpers.InternalError), http.StatusInternalServerError)
+			return
+		}
+		if !owns {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Forbidden: this is not your booking series", helpers.Forbidden), http.StatusForbidden)
+			return
+		}
+	}
+
+	seriesBookings, err := h.store.ListSeriesBookings(ctx, series.ID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to list bookings of series %s", series.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get bookings", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteData(ctx, resp, GetBookingSeriesResponse{Series: series, Bookings: seriesBookings}, http.StatusOK)
+}
+
+// cancelSeries cancels the occurrences of the booking's series selected by scope, all or none of them.
+func (h *Handler) cancelSeries(resp http.ResponseWriter, req *http.Request, booking *bookings.Booking, actor *bookingActor,
+	scope bookings.SeriesScope, to bookings.Status) {
+	ctx := req.Context()
+
+	targets, ok := h.seriesTargets(resp, req, booking, actor, scope)
+	if !ok {
+		return
+	}
+
+	ids := make([]string, 0, len(targets))
+	for _, target := range targets {
+		ids = append(ids, target.ID)
+	}
+
+	changed, err := h.store.TransitionBookings(ctx, ids, to, actor.UserID)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to cancel bookings")
+		return
+	}
+	h.offerFreedSlots(ctx, changed...)
+
+	helpers.WriteData(ctx, resp, SeriesChangeResponse{Bookings: changed}, http.StatusOK)
+}
+
+// rescheduleSeries moves the occurrences of the booking's series selected by scope along with the booking.
+// Every moved occurrence must fit the rules of a new booking; if any of them does not, or would overlap
+// another booking, none is moved.
+func (h *Handler) rescheduleSeries(resp http.ResponseWriter, req *http.Request, booking *bookings.Booking, actor *bookingActor,
+	scope bookings.SeriesScope, rescheduleReq bookings.RescheduleBookingRequest) {
+	ctx := req.Context()
+
+	targets, ok := h.seriesTargets(resp, req, booking, actor, scope)
+	if !ok {
+		return
+	}
+
+	rules, errs, err := h.rules.LoadForBooking(ctx, booking)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to validate reschedule of booking series %s", *booking.SeriesID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate booking", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	if len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	// Every occurrence is checked like a single reschedule; one that cannot move keeps all of them in place
+	now := time.Now()
+	changes := make([]bookings.BookingReschedule, 0, len(targets))
+	var conflicts []bookings.OccurrenceConflict
+	for _, target := range targets {
+		// Every occurrence keeps its duration
+		service := *rules.Service
+		service.DurationMinutes = int(target.EndTime.Sub(target.StartTime) / time.Minute)
+
+		moved := bookings.CreateBookingRequest{
+			StartTime: schedules.Shift(target.StartTime, booking.StartTime, rescheduleReq.StartTime, rules.Calendar.Location),
+		}
+		if errs := bookingrules.CheckSlot(&service, rules.Calendar, &moved, now); len(errs) > 0 {
+			conflicts = append(conflicts, bookings.OccurrenceConflict{
+				TimeWindow: bookings.TimeWindow{StartTime: moved.StartTime, EndTime: moved.StartTime.Add(target.EndTime.Sub(target.StartTime))},
+				Reason:     errs.Error(),
+			})
+			continue
+		}
+		changes = append(changes, bookings.BookingReschedule{
+			BookingID:                target.ID,
+			RescheduleBookingRequest: bookings.RescheduleBookingRequest{StartTime: moved.StartTime, EndTime: moved.EndTime},
+		})
+	}
+	if len(conflicts) > 0 {
+		helpers.WriteErrorResponse(
+			resp,
+			helpers.NewErrorResponse("Some occurrences of the series cannot be moved", helpers.ValidationError).WithDetails(conflicts),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	changed, err := h.store.RescheduleBookings(ctx, changes, actor.UserID)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to reschedule bookings")
+		return
+	}
+	// Targets still hold the old windows
+	h.offerFreedSlots(ctx, targets...)
+
+	helpers.WriteData(ctx, resp, SeriesChangeResponse{Bookings: changed}, http.StatusOK)
+}
+
+// seriesTargets resolves the bookings of a series change. Customers may only change occurrences outside
+// of the cancellation cutoff. Error responses are written here.
+func (h *Handler) seriesTargets(resp http.ResponseWriter, req *http.Request, booking *bookings.Booking, actor *bookingActor,
+	scope bookings.SeriesScope) ([]*bookings.Booking, bool) {
+	ctx := req.Context()
+
+	if booking.SeriesID == nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("booking is not part of a series", helpers.ValidationError), http.StatusBadRequest)
+		return nil, false
+	}
+
+	seriesBookings, err := h.store.ListSeriesBookings(ctx, *booking.SeriesID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to list bookings of series %s", *booking.SeriesID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get bookings", helpers.InternalError), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	targets := bookings.InScope(seriesBookings, booking, scope, time.Now())
+	if !actor.Business {
+		// Targets are ordered by start time, so the first one is the closest to the cutoff
+		if !h.checkCancellationCutoff(resp, req, targets[0]) {
+			return nil, false
+		}
+	}
+
+	return targets, true
+}
@@ -0,0 +1,204 @@
+package bookings
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/bookings"
+)
+
+// maxBookingServices caps the number of services booked together in one appointment.
+const maxBookingServices = 5
+
+func validateCreateBookingRequest(req bookings.CreateBookingRequest) helpers.FieldErrors {
+	var errs helpers.FieldErrors
+	if req.BusinessID == "" {
+		errs.Add("business_id", "is required")
+	}
+	switch {
+	case len(req.ServiceIDs) == 0 && req.ServiceID == "":
+		errs.Add("service_id", "is required")
+	case len(req.ServiceIDs) > maxBookingServices:
+		errs.Add("service_ids", fmt.Sprintf("at most %d services can be booked together", maxBookingServices))
+	case len(req.ServiceIDs) > 0 && req.ServiceID != "" && req.ServiceID != req.ServiceIDs[0]:
+		errs.Add("service_id", "must be the first of service_ids when both are given")
+	case slices.Contains(req.ServiceIDs, ""):
+		errs.Add("service_ids", "cannot contain empty ids")
+	case hasDuplicates(req.ServiceIDs):
+		errs.Add("service_ids", "cannot contain the same service twice")
+	}
+	if slices.Contains(req.VariantIDs, "") {
+		errs.Add("variant_ids", "cannot contain empty ids")
+	}
+	if slices.Contains(req.AddOnIDs, "") {
+		errs.Add("add_on_ids", "cannot contain empty ids")
+	}
+	if req.StartTime.IsZero() {
+		errs.Add("start_time", "is required")
+	} else if req.StartTime.Before(time.Now()) {
+		errs.Add("start_time", "cannot create booking in the past")
+	}
+	if !req.EndTime.IsZero() && !req.EndTime.After(req.StartTime) {
+		errs.Add("end_time", "must be after start_time")
+	}
+	return errs
+}
+
+// hasDuplicates reports whether an id occurs more than once.
+func hasDuplicates(ids []string) bool {
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return true
+		}
+		seen[id] = true
+	}
+	return false
+}
+
+// bookedServiceIDs returns the services of a valid create request in the order they are performed.
+func bookedServiceIDs(req bookings.CreateBookingRequest) []string {
+	if len(req.ServiceIDs) > 0 {
+		return req.ServiceIDs
+	}
+	return []string{req.ServiceID}
+}
+
+func validateRescheduleBookingRequest(req bookings.RescheduleBookingRequest) error {
+	if req.StartTime.IsZero() {
+		return helpers.NewValidationError("start_time is required")
+	}
+	if !req.EndTime.IsZero() && !req.EndTime.After(req.StartTime) {
+		return helpers.NewValidationError("end_time must be after start_time")
+	}
+	if req.StartTime.Before(time.Now()) {
+		return helpers.NewValidationError("cannot reschedule booking to the past")
+	}
+	return nil
+}
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// parseListBookingsQueries builds a list request out of the status, scope, from, to, order, cursor
+// and limit query parameters. Statuses are comma-separated; dates are RFC 3339.
+func parseListBookingsQueries(queries url.Values) (*bookings.ListBookingsRequest, error) {
+	listReq := &bookings.ListBookingsRequest{
+		Limit:  defaultListLimit,
+		Cursor: queries.Get("cursor"),
+	}
+
+	if statuses := queries.Get("status"); statuses != "" {
+		for _, s := range strings.Split(statuses, ",") {
+			status, ok := bookings.StringToStatus(strings.TrimSpace(s))
+			if !ok {
+				return nil, helpers.NewValidationError("invalid status: " + s)
+			}
+			listReq.Statuses = append(listReq.Statuses, status)
+		}
+	}
+
+	switch scope := bookings.ListScope(queries.Get("scope")); scope {
+	case bookings.ScopeAll, bookings.ScopeUpcoming:
+		listReq.Scope = scope
+	case bookings.ScopePast:
+		listReq.Scope = scope
+		// History is browsed from the most recent booking backwards
+		listReq.Descending = true
+	default:
+		return nil, helpers.NewValidationError("scope must be either upcoming or past")
+	}
+
+	switch order := queries.Get("order"); order {
+	case "":
+	case "asc":
+		listReq.Descending = false
+	case "desc":
+		listReq.Descending = true
+	default:
+		return nil, helpers.NewValidationError("order must be either asc or desc")
+	}
+
+	for param, target := range map[string]**time.Time{"from": &listReq.From, "to": &listReq.To} {
+		if value := queries.Get(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, helpers.NewValidationError(param + " must be an RFC 3339 timestamp")
+			}
+			*target = &t
+		}
+	}
+	if listReq.From != nil && listReq.To != nil && !listReq.To.After(*listReq.From) {
+		return nil, helpers.NewValidationError("to must be after from")
+	}
+
+	if limitStr := queries.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return nil, helpers.NewValidationError("limit must be greater than 0")
+		}
+		if limit > maxListLimit {
+			return nil, helpers.NewValidationError("limit cannot exceed 100")
+		}
+		listReq.Limit = limit
+	}
+
+	return listReq, nil
+}
+
+// maxSeriesOccurrences caps the number of bookings a single series materialises.
+const maxSeriesOccurrences = 52
+
+func validateCreateSeriesRequest(req bookings.CreateSeriesRequest) helpers.FieldErrors {
+	var errs helpers.FieldErrors
+	if req.BusinessID == "" {
+		errs.Add("business_id", "is required")
+	}
+	if req.ServiceID == "" {
+		errs.Add("service_id", "is required")
+	}
+	if req.StartTime.IsZero() {
+		errs.Add("start_time", "is required")
+	} else if req.StartTime.Before(time.Now()) {
+		errs.Add("start_time", "cannot create booking in the past")
+	}
+
+	rec := req.Recurrence
+	if _, ok := bookings.StringToFrequency(string(rec.Frequency)); !ok {
+		errs.Add("recurrence.frequency", "must be one of daily, weekly or monthly")
+	}
+	if rec.Interval < 0 {
+		errs.Add("recurrence.interval", "cannot be negative")
+	}
+	if rec.Count < 0 || rec.Count > maxSeriesOccurrences {
+		errs.Add("recurrence.count", "must be between 1 and "+strconv.Itoa(maxSeriesOccurrences))
+	}
+	if rec.Count == 0 && rec.Until == nil {
+		errs.Add("recurrence", "either count or until is required")
+	}
+	if rec.Until != nil && !rec.Until.After(req.StartTime) {
+		errs.Add("recurrence.until", "must be after start_time")
+	}
+	return errs
+}
+
+// parseSeriesScope reads the scope query parameter of a change to a booking; it defaults to this booking only.
+func parseSeriesScope(queries url.Values) (bookings.SeriesScope, error) {
+	value := queries.Get("scope")
+	if value == "" {
+		return bookings.ScopeThis, nil
+	}
+
+	scope, ok := bookings.StringToSeriesScope(value)
+	if !ok {
+		return "", helpers.NewValidationError("scope must be one of this, following or all")
+	}
+	return scope, nil
+}
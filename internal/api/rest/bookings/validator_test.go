@@ -0,0 +1,48 @@
+package bookings
+
+import (
+	"testing"
+	"time"
+
+	"booking-service/internal/store/bookings"
+)
+
+func TestValidateCreateBookingRequest_ServiceIDs(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		serviceID  string
+		serviceIDs []string
+		wantField  string
+	}{
+		{name: "single service", serviceID: "cut"},
+		{name: "several services", serviceIDs: []string{"cut", "color"}},
+		{name: "no service", wantField: "service_id"},
+		{name: "first service differs", serviceID: "color", serviceIDs: []string{"cut", "color"}, wantField: "service_id"},
+		{name: "empty id", serviceIDs: []string{"cut", ""}, wantField: "service_ids"},
+		{name: "same service twice", serviceIDs: []string{"cut", "color", "cut"}, wantField: "service_ids"},
+		{name: "too many services", serviceIDs: []string{"a", "b", "c", "d", "e", "f"}, wantField: "service_ids"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateCreateBookingRequest(bookings.CreateBookingRequest{
+				BusinessID: "salon",
+				ServiceID:  tt.serviceID,
+				ServiceIDs: tt.serviceIDs,
+				StartTime:  start,
+			})
+
+			if tt.wantField == "" {
+				if len(errs) > 0 {
+					t.Errorf("validateCreateBookingRequest() = %v, want no errors", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Field != tt.wantField {
+				t.Errorf("validateCreateBookingRequest() = %v, want an error on %s", errs, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package business_account
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/business_accounts"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+const maxCalendarRangeDays = 31
+
+// unassignedSpecialist groups the bookings that are not tied to a specialist.
+const unassignedSpecialist = ""
+
+type CalendarResponse struct {
+	BusinessAccountID string        `json:"business_account_id"`
+	Timezone          string        `json:"timezone"`
+	Days              []CalendarDay `json:"days"`
+}
+
+type CalendarDay struct {
+	Date        string               `json:"date"`
+	Specialists []SpecialistCalendar `json:"specialists"`
+}
+
+type SpecialistCalendar struct {
+	SpecialistID *string                   `json:"specialist_id"`
+	Bookings     []*bookings.CalendarEntry `json:"bookings"`
+}
+
+// GetBookingsCalendar returns the bookings of the business between the from and to dates (inclusive,
+// YYYY-MM-DD in the business timezone), grouped by day and then by specialist.
+func (h *Handler) GetBookingsCalendar(w http.ResponseWriter, r *http.Request) {
+	businessAccountID := mux.Vars(r)["id"]
+	ctx := r.Context()
+
+	if !h.authorizeOwner(w, r, businessAccountID) {
+		return
+	}
+
+	dates, ok := h.parseCalendarRange(w, r, businessAccountID)
+	if !ok {
+		return
+	}
+
+	entries, err := h.bookingsStore.ListBusinessBookings(ctx, bookings.ListBusinessBookingsRequest{
+		BusinessID:       businessAccountID,
+		From:             dates.From,
+		To:               dates.To,
+		IncludeCancelled: r.URL.Query().Get("include_cancelled") == "true",
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to list bookings of business account %s", businessAccountID)
+		http.Error(w, "Failed to list bookings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CalendarResponse{
+		BusinessAccountID: businessAccountID,
+		Timezone:          dates.Timezone,
+		Days:              groupCalendar(entries, dates.Location),
+	})
+}
+
+// calendarRange is the time range covered by the from and to dates of a calendar request.
+type calendarRange struct {
+	Timezone string
+	Location *time.Location
+	// From is the start of the from date, To the end of the to date.
+	From time.Time
+	To   time.Time
+}
+
+// parseCalendarRange reads the from and to dates (inclusive, YYYY-MM-DD in the business timezone) of the request.
+// Error responses are written here.
+func (h *Handler) parseCalendarRange(w http.ResponseWriter, r *http.Request, businessAccountID string) (*calendarRange, bool) {
+	ctx := r.Context()
+
+	timezone, err := h.store.GetTimezone(ctx, businessAccountID)
+	if err != nil {
+		if errors.Is(err, business_accounts.NotFoundError) {
+			http.Error(w, "Business account not found", http.StatusNotFound)
+			return nil, false
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to get timezone of business account %s", businessAccountID)
+		http.Error(w, "Failed to get business account", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Invalid timezone of business account %s", businessAccountID)
+		http.Error(w, "Invalid business timezone", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	queries := r.URL.Query()
+	from, err := time.ParseInLocation(dateLayout, queries.Get("from"), loc)
+	if err != nil {
+		http.Error(w, "from must be a date in YYYY-MM-DD format", http.StatusBadRequest)
+		return nil, false
+	}
+	to, err := time.ParseInLocation(dateLayout, queries.Get("to"), loc)
+	if err != nil {
+		http.Error(w, "to must be a date in YYYY-MM-DD format", http.StatusBadRequest)
+		return nil, false
+	}
+	if to.Before(from) || to.After(from.AddDate(0, 0, maxCalendarRangeDays-1)) {
+		http.Error(w, "to must be within 31 days after from", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return &calendarRange{Timezone: timezone, Location: loc, From: from, To: to.AddDate(0, 0, 1)}, true
+}
+
+// groupCalendar groups entries ordered by start time into days of the given location and, within a day,
+// by specialist in order of their first booking.
+func groupCalendar(entries []*bookings.CalendarEntry, loc *time.Location) []CalendarDay {
+	days := []CalendarDay{}
+	var specialistIndex map[string]int
+
+	for _, entry := range entries {
+		date := entry.StartTime.In(loc).Format(dateLayout)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, CalendarDay{Date: date, Specialists: []SpecialistCalendar{}})
+			specialistIndex = make(map[string]int)
+		}
+		day := &days[len(days)-1]
+
+		key := unassignedSpecialist
+		if entry.SpecialistID != nil {
+			key = *entry.SpecialistID
+		}
+
+		i, ok := specialistIndex[key]
+		if !ok {
+			i = len(day.Specialists)
+			specialistIndex[key] = i
+			day.Specialists = append(day.Specialists, SpecialistCalendar{SpecialistID: entry.SpecialistID})
+		}
+		day.Specialists[i].Bookings = append(day.Specialists[i].Bookings, entry)
+	}
+
+	return days
+}
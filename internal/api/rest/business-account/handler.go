@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"booking-service/internal/api/rest/bookingrules"
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/bookings"
 	"booking-service/internal/store/business_accounts"
-	utoken "booking-service/pkg/utils/token"
+	"booking-service/internal/store/users"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -13,12 +16,19 @@ import (
 )
 
 type Handler struct {
-	store business_accounts.Store
+	store         business_accounts.Store
+	bookingsStore bookings.Store
+	bookingRules  *bookingrules.Loader
+	usersStore    users.Store
 }
 
-func NewHandler(store business_accounts.Store) *Handler {
+func NewHandler(store business_accounts.Store, bookingsStore bookings.Store, bookingRules *bookingrules.Loader,
+	usersStore users.Store) *Handler {
 	return &Handler{
-		store: store,
+		store:         store,
+		bookingsStore: bookingsStore,
+		bookingRules:  bookingRules,
+		usersStore:    usersStore,
 	}
 }
 
@@ -27,7 +37,6 @@ type CreateBusinessAccountRequest struct {
 	BusinessType string          `json:"businessType"`
 	Location     string          `json:"location"`
 	Links        json.RawMessage `json:"links"`
-	UserID       string          `json:"userId"`
 }
 
 type UpdateBusinessAccountRequest struct {
@@ -44,14 +53,21 @@ func (h *Handler) CreateBusinessAccount(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// The caller becomes the owner of the new business account
+	userID, ok := helpers.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized: invalid token", http.StatusUnauthorized)
+		return
+	}
+
 	var req CreateBusinessAccountRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if req.Name == "" || req.BusinessType == "" || req.Location == "" || req.UserID == "" {
-		http.Error(w, "Name, business type, location and user ID are required", http.StatusBadRequest)
+	if req.Name == "" || req.BusinessType == "" || req.Location == "" {
+		http.Error(w, "Name, business type and location are required", http.StatusBadRequest)
 		return
 	}
 
@@ -63,7 +79,7 @@ func (h *Handler) CreateBusinessAccount(w http.ResponseWriter, r *http.Request)
 		Links:        req.Links,
 	}
 
-	if err := h.store.CreateBusinessAccount(r.Context(), account, req.UserID); err != nil {
+	if err := h.store.CreateBusinessAccount(r.Context(), account, userID); err != nil {
 		http.Error(w, "Failed to create business account", http.StatusInternalServerError)
 		return
 	}
@@ -86,10 +102,8 @@ func (h *Handler) UpdateBusinessAccount(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	// Extract user ID from JWT (Authorization header)
-	token := r.Header.Get("Authorization")
-	userID, err := utoken.ExtractUserIDFromJWT(token)
-	if err != nil {
+	userID, ok := helpers.UserIDFromContext(r.Context())
+	if !ok {
 		http.Error(w, "Unauthorized: invalid token", http.StatusUnauthorized)
 		return
 	}
@@ -141,10 +155,8 @@ func (h *Handler) DeleteBusinessAccount(w http.ResponseWriter, r *http.Request)
 	}
 	ctx := r.Context()
 
-	// Extract user ID from JWT (Authorization header)
-	token := r.Header.Get("Authorization")
-	userID, err := utoken.ExtractUserIDFromJWT(token)
-	if err != nil {
+	userID, ok := helpers.UserIDFromContext(r.Context())
+	if !ok {
 		http.Error(w, "Unauthorized: invalid token", http.StatusUnauthorized)
 		return
 	}
@@ -198,3 +210,24 @@ func (h *Handler) GetBusinessAccount(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(account)
 }
+
+// authorizeOwner checks that the caller owns the business account and writes the error response if not.
+func (h *Handler) authorizeOwner(w http.ResponseWriter, r *http.Request, businessAccountID string) bool {
+	userID, ok := helpers.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized: invalid token", http.StatusUnauthorized)
+		return false
+	}
+
+	owns, err := h.store.UserOwnsBusinessAccount(r.Context(), businessAccountID, userID)
+	if err != nil {
+		http.Error(w, "Failed to validate ownership", http.StatusInternalServerError)
+		return false
+	}
+	if !owns {
+		http.Error(w, "Forbidden: you do not own this business account", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,133 @@
+package business_account
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"booking-service/internal/store/business_accounts"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+type SetWeeklyHoursRequest struct {
+	Timezone string                              `json:"timezone"`
+	Weekly   []business_accounts.WorkingInterval `json:"weekly"`
+}
+
+type SetHoursOverrideRequest struct {
+	Closed    bool                             `json:"closed"`
+	Intervals []business_accounts.TimeInterval `json:"intervals"`
+}
+
+func (h *Handler) GetWorkingHours(w http.ResponseWriter, r *http.Request) {
+	businessAccountID := mux.Vars(r)["id"]
+	ctx := r.Context()
+
+	hours, err := h.store.GetWorkingHours(ctx, businessAccountID)
+	if err != nil {
+		if errors.Is(err, business_accounts.NotFoundError) {
+			http.Error(w, "Business account not found", http.StatusNotFound)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to get working hours of business account %s", businessAccountID)
+		http.Error(w, "Failed to get working hours", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hours)
+}
+
+func (h *Handler) SetWeeklyHours(w http.ResponseWriter, r *http.Request) {
+	businessAccountID := mux.Vars(r)["id"]
+	ctx := r.Context()
+
+	if !h.authorizeOwner(w, r, businessAccountID) {
+		return
+	}
+
+	var req SetWeeklyHoursRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Timezone == "" {
+		req.Timezone = business_accounts.DefaultTimezone
+	}
+
+	if err := validateSetWeeklyHoursRequest(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.SetWeeklyHours(ctx, businessAccountID, req.Timezone, req.Weekly); err != nil {
+		if errors.Is(err, business_accounts.NotFoundError) {
+			http.Error(w, "Business account not found", http.StatusNotFound)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to set working hours of business account %s", businessAccountID)
+		http.Error(w, "Failed to set working hours", http.StatusInternalServerError)
+		return
+	}
+
+	h.GetWorkingHours(w, r)
+}
+
+func (h *Handler) SetHoursOverride(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	businessAccountID := vars["id"]
+	ctx := r.Context()
+
+	if !h.authorizeOwner(w, r, businessAccountID) {
+		return
+	}
+
+	var req SetHoursOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	override := business_accounts.HoursOverride{
+		Date:      vars["date"],
+		Closed:    req.Closed,
+		Intervals: req.Intervals,
+	}
+	if err := validateHoursOverride(override); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.SetHoursOverride(ctx, businessAccountID, override); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to set working hours override of business account %s", businessAccountID)
+		http.Error(w, "Failed to set working hours override", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(override)
+}
+
+func (h *Handler) DeleteHoursOverride(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	businessAccountID := vars["id"]
+	ctx := r.Context()
+
+	if !h.authorizeOwner(w, r, businessAccountID) {
+		return
+	}
+
+	if err := h.store.DeleteHoursOverride(ctx, businessAccountID, vars["date"]); err != nil {
+		if errors.Is(err, business_accounts.OverrideNotFoundError) {
+			http.Error(w, "Working hours override not found", http.StatusNotFound)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to delete working hours override of business account %s", businessAccountID)
+		http.Error(w, "Failed to delete working hours override", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,171 @@
+package business_account
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/mail"
+	"time"
+
+	"booking-service/internal/api/rest/bookingrules"
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/users"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// CreateManualBookingRequest is an appointment added by the business itself. The customer is either
+// a registered user (UserID) or a guest known only by name and contact details.
+type CreateManualBookingRequest struct {
+	UserID       string          `json:"user_id,omitempty"`
+	Guest        *bookings.Guest `json:"guest,omitempty"`
+	ServiceID    string          `json:"service_id"`
+	SpecialistID *string         `json:"specialist_id,omitempty"`
+	StartTime    time.Time       `json:"start_time"`
+	// EndTime is derived from the duration of the service; a client-sent end time must match it.
+	EndTime time.Time `json:"end_time,omitempty"`
+}
+
+// CreateManualBooking adds a booking of the business' own service and specialist. It must fit the opening
+// hours and the working time of the specialist, but unlike customers the owner is not bound to the lead
+// time and booking horizon of the service.
+func (h *Handler) CreateManualBooking(w http.ResponseWriter, r *http.Request) {
+	businessAccountID := mux.Vars(r)["id"]
+	ctx := r.Context()
+
+	if !h.authorizeOwner(w, r, businessAccountID) {
+		return
+	}
+
+	var req CreateManualBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateCreateManualBookingRequest(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.UserID != "" && !h.checkCustomer(w, r, req.UserID) {
+		return
+	}
+
+	createReq := bookings.CreateBookingRequest{
+		UserID:       req.UserID,
+		Guest:        req.Guest,
+		Source:       bookings.SourceManual,
+		BusinessID:   businessAccountID,
+		SpecialistID: req.SpecialistID,
+		ServiceID:    req.ServiceID,
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+	}
+
+	rules, errs, err := h.bookingRules.Load(ctx, businessAccountID, req.SpecialistID, []string{req.ServiceID}, nil, nil)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to validate manual booking of service %s", req.ServiceID)
+		http.Error(w, "Failed to validate booking", http.StatusInternalServerError)
+		return
+	}
+	if len(errs) > 0 {
+		helpers.WriteFieldErrors(w, errs)
+		return
+	}
+	service := *rules.Service
+	service.MinLeadMinutes, service.MaxHorizonDays = 0, 0
+	createReq.Items = rules.Items
+	if errs := bookingrules.CheckSlot(&service, rules.Calendar, &createReq, time.Now()); len(errs) > 0 {
+		helpers.WriteFieldErrors(w, errs)
+		return
+	}
+
+	booking, err := h.bookingsStore.CreateBooking(ctx, createReq)
+	if err != nil {
+		var overlapErr *bookings.OverlapError
+		if errors.As(err, &overlapErr) {
+			helpers.WriteErrorResponse(
+				w,
+				helpers.NewErrorResponse(overlapErr.Error(), helpers.Conflict).WithDetails(overlapErr.Conflicting),
+				http.StatusConflict,
+			)
+			return
+		}
+		if errors.Is(err, bookings.ErrSessionFull) || errors.Is(err, bookings.ErrAlreadyAttending) {
+			helpers.WriteErrorResponse(w, helpers.NewErrorResponse(err.Error(), helpers.Conflict), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, bookings.ErrServiceNotFound) {
+			http.Error(w, "Service not found", http.StatusBadRequest)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to create manual booking for business account %s", businessAccountID)
+		http.Error(w, "Failed to create booking", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(booking)
+}
+
+// checkCustomer checks that the customer of a manual booking is a registered user. Error responses are
+// written here.
+func (h *Handler) checkCustomer(w http.ResponseWriter, r *http.Request, userID string) bool {
+	ctx := r.Context()
+
+	var errs helpers.FieldErrors
+	if _, err := uuid.Parse(userID); err != nil {
+		errs.Add("user_id", "must be the id of a user account")
+		helpers.WriteFieldErrors(w, errs)
+		return false
+	}
+
+	if _, err := h.usersStore.GetUser(ctx, userID); err != nil {
+		if errors.Is(err, users.ErrUserNotFound) {
+			errs.Add("user_id", "user account not found")
+			helpers.WriteFieldErrors(w, errs)
+			return false
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to get customer %s of manual booking", userID)
+		http.Error(w, "Failed to get customer", http.StatusInternalServerError)
+		return false
+	}
+
+	return true
+}
+
+func validateCreateManualBookingRequest(req CreateManualBookingRequest) error {
+	if req.ServiceID == "" {
+		return errors.New("service_id is required")
+	}
+	if req.StartTime.IsZero() {
+		return errors.New("start_time is required")
+	}
+
+	if req.UserID != "" && req.Guest != nil {
+		return errors.New("either user_id or guest must be set, not both")
+	}
+	if req.UserID == "" && req.Guest == nil {
+		return errors.New("either user_id or guest is required")
+	}
+
+	if guest := req.Guest; guest != nil {
+		if guest.Name == "" {
+			return errors.New("guest name is required")
+		}
+		if guest.Phone == nil && guest.Email == nil {
+			return errors.New("guest phone or email is required")
+		}
+		if guest.Email != nil {
+			if _, err := mail.ParseAddress(*guest.Email); err != nil {
+				return errors.New("guest email is invalid")
+			}
+		}
+	}
+
+	return nil
+}
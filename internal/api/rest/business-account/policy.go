@@ -0,0 +1,81 @@
+package business_account
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"booking-service/internal/assignment"
+	"booking-service/internal/store/business_accounts"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+func (h *Handler) GetBookingPolicy(w http.ResponseWriter, r *http.Request) {
+	businessAccountID := mux.Vars(r)["id"]
+	ctx := r.Context()
+
+	policy, err := h.store.GetBookingPolicy(ctx, businessAccountID)
+	if err != nil {
+		if errors.Is(err, business_accounts.NotFoundError) {
+			http.Error(w, "Business account not found", http.StatusNotFound)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to get booking policy of business account %s", businessAccountID)
+		http.Error(w, "Failed to get booking policy", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+func (h *Handler) UpdateBookingPolicy(w http.ResponseWriter, r *http.Request) {
+	businessAccountID := mux.Vars(r)["id"]
+	ctx := r.Context()
+
+	if !h.authorizeOwner(w, r, businessAccountID) {
+		return
+	}
+
+	// Fields left out of the body keep their stored values
+	policy, err := h.store.GetBookingPolicy(ctx, businessAccountID)
+	if err != nil {
+		if errors.Is(err, business_accounts.NotFoundError) {
+			http.Error(w, "Business account not found", http.StatusNotFound)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to get booking policy of business account %s", businessAccountID)
+		http.Error(w, "Failed to get booking policy", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(policy); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if policy.CancellationCutoffMinutes < 0 {
+		http.Error(w, "cancellation_cutoff_minutes cannot be negative", http.StatusBadRequest)
+		return
+	}
+	if _, ok := assignment.ByName(policy.AssignmentStrategy); !ok {
+		http.Error(w, fmt.Sprintf("assignment_strategy must be one of %s", strings.Join(assignment.Names(), ", ")), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateBookingPolicy(ctx, businessAccountID, *policy); err != nil {
+		if errors.Is(err, business_accounts.NotFoundError) {
+			http.Error(w, "Business account not found", http.StatusNotFound)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to update booking policy of business account %s", businessAccountID)
+		http.Error(w, "Failed to update booking policy", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
@@ -21,4 +21,19 @@ func (r Router) RegisterRoutes(router *mux.Router) {
 	bookingRouter.HandleFunc("/{id}", r.handler.UpdateBusinessAccount).Methods("PUT")
 	bookingRouter.HandleFunc("/{id}", r.handler.DeleteBusinessAccount).Methods("DELETE")
 	bookingRouter.HandleFunc("/{id}", r.handler.GetBusinessAccount).Methods("GET")
+
+	// Working hours and date-specific overrides
+	bookingRouter.HandleFunc("/{id}/hours", r.handler.GetWorkingHours).Methods("GET")
+	bookingRouter.HandleFunc("/{id}/hours", r.handler.SetWeeklyHours).Methods("PUT")
+	bookingRouter.HandleFunc("/{id}/hours/overrides/{date}", r.handler.SetHoursOverride).Methods("PUT")
+	bookingRouter.HandleFunc("/{id}/hours/overrides/{date}", r.handler.DeleteHoursOverride).Methods("DELETE")
+
+	// Booking calendar of the business
+	bookingRouter.HandleFunc("/{id}/bookings", r.handler.GetBookingsCalendar).Methods("GET")
+	bookingRouter.HandleFunc("/{id}/bookings", r.handler.CreateManualBooking).Methods("POST")
+	bookingRouter.HandleFunc("/{id}/sessions", r.handler.GetClassSessions).Methods("GET")
+
+	// Customer-facing booking rules, e.g. the cancellation cutoff
+	bookingRouter.HandleFunc("/{id}/booking-policy", r.handler.GetBookingPolicy).Methods("GET")
+	bookingRouter.HandleFunc("/{id}/booking-policy", r.handler.UpdateBookingPolicy).Methods("PUT")
 }
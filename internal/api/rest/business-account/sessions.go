@@ -0,0 +1,91 @@
+package business_account
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"booking-service/internal/store/bookings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+type SessionsResponse struct {
+	BusinessAccountID string             `json:"business_account_id"`
+	Timezone          string             `json:"timezone"`
+	Sessions          []SessionAttendees `json:"sessions"`
+}
+
+// SessionAttendees is a class session with the customers attending it.
+type SessionAttendees struct {
+	bookings.ClassSession
+	SpotsLeft int                       `json:"spots_left"`
+	Attendees []*bookings.CalendarEntry `json:"attendees"`
+}
+
+// GetClassSessions returns the group class sessions of the business between the from and to dates (inclusive,
+// YYYY-MM-DD in the business timezone) with their attendee lists.
+func (h *Handler) GetClassSessions(w http.ResponseWriter, r *http.Request) {
+	businessAccountID := mux.Vars(r)["id"]
+	ctx := r.Context()
+
+	if !h.authorizeOwner(w, r, businessAccountID) {
+		return
+	}
+
+	dates, ok := h.parseCalendarRange(w, r, businessAccountID)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.bookingsStore.ListSessions(ctx, businessAccountID, dates.From, dates.To)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to list class sessions of business account %s", businessAccountID)
+		http.Error(w, "Failed to list class sessions", http.StatusInternalServerError)
+		return
+	}
+
+	// Attendees start with their session, so the bookings of the same dates cover all of them
+	entries, err := h.bookingsStore.ListBusinessBookings(ctx, bookings.ListBusinessBookingsRequest{
+		BusinessID: businessAccountID,
+		From:       dates.From,
+		To:         dates.To,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Failed to list bookings of business account %s", businessAccountID)
+		http.Error(w, "Failed to list bookings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SessionsResponse{
+		BusinessAccountID: businessAccountID,
+		Timezone:          dates.Timezone,
+		Sessions:          groupAttendees(sessions, entries),
+	})
+}
+
+// groupAttendees attaches the bookings to the sessions they attend. Bookings outside of sessions are left out.
+func groupAttendees(sessions []*bookings.ClassSession, entries []*bookings.CalendarEntry) []SessionAttendees {
+	result := make([]SessionAttendees, 0, len(sessions))
+	index := make(map[string]int, len(sessions))
+	for i, session := range sessions {
+		index[session.ID] = i
+		result = append(result, SessionAttendees{
+			ClassSession: *session,
+			SpotsLeft:    session.SpotsLeft(),
+			Attendees:    []*bookings.CalendarEntry{},
+		})
+	}
+
+	for _, entry := range entries {
+		if entry.SessionID == nil {
+			continue
+		}
+		if i, ok := index[*entry.SessionID]; ok {
+			result[i].Attendees = append(result[i].Attendees, entry)
+		}
+	}
+
+	return result
+}
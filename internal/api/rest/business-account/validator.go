@@ -0,0 +1,67 @@
+package business_account
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"booking-service/internal/schedules"
+	"booking-service/internal/store/business_accounts"
+)
+
+const dateLayout = "2006-01-02"
+
+func validateSetWeeklyHoursRequest(req SetWeeklyHoursRequest) error {
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", req.Timezone)
+	}
+
+	byDay := make(map[time.Weekday][]business_accounts.TimeInterval)
+	for _, interval := range req.Weekly {
+		if interval.Weekday < time.Sunday || interval.Weekday > time.Saturday {
+			return fmt.Errorf("weekday must be between 0 (Sunday) and 6 (Saturday), got %d", interval.Weekday)
+		}
+		byDay[interval.Weekday] = append(byDay[interval.Weekday], interval.TimeInterval)
+	}
+
+	for day, intervals := range byDay {
+		if err := validateIntervals(intervals); err != nil {
+			return fmt.Errorf("%s: %w", day, err)
+		}
+	}
+	return nil
+}
+
+func validateHoursOverride(override business_accounts.HoursOverride) error {
+	if _, err := time.Parse(dateLayout, override.Date); err != nil {
+		return errors.New("date must be in YYYY-MM-DD format")
+	}
+	if override.Closed && len(override.Intervals) > 0 {
+		return errors.New("a closed day cannot have opening intervals")
+	}
+	if !override.Closed && len(override.Intervals) == 0 {
+		return errors.New("intervals are required unless the day is closed")
+	}
+	return validateIntervals(override.Intervals)
+}
+
+// validateIntervals checks that every interval is well-formed and that intervals of one day do not overlap.
+func validateIntervals(intervals []business_accounts.TimeInterval) error {
+	ranges := make([]schedules.TimeRange, 0, len(intervals))
+	for _, interval := range intervals {
+		r, err := schedules.ParseTimeInterval(interval)
+		if err != nil {
+			return err
+		}
+		ranges = append(ranges, r)
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i].Start < ranges[i-1].End {
+			return errors.New("opening intervals must not overlap")
+		}
+	}
+	return nil
+}
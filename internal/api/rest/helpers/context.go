@@ -0,0 +1,18 @@
+package helpers
+
+import "context"
+
+type contextKey int
+
+const userIDKey contextKey = iota
+
+// WithUserID returns a copy of the context carrying the ID of the authenticated user.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext returns the ID of the user authenticated by the auth middleware.
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDKey).(string)
+	return userID, ok && userID != ""
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 )
@@ -31,12 +32,15 @@ const (
 	ValidationError
 	NotFound
 	InternalError
+	Forbidden
+	Conflict
 )
 
 type ErrorResponse struct {
 	Message string
 	Type    string
 	Code    ErrorCode
+	Details interface{} `json:"Details,omitempty"`
 }
 
 // ValidationErr represents a validation error
@@ -52,6 +56,33 @@ func NewValidationError(message string) error {
 	return &ValidationErr{Message: message}
 }
 
+// FieldError describes a validation problem of a single request field.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// FieldErrors collects all validation problems of a request, so clients can report them at once.
+// They are sent as the Details of a ValidationError response.
+type FieldErrors []FieldError
+
+func (e *FieldErrors) Add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fe := range e {
+		messages = append(messages, fe.Field+": "+fe.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// WriteFieldErrors writes a 400 ValidationError response listing the field errors.
+func WriteFieldErrors(w http.ResponseWriter, errs FieldErrors) {
+	WriteErrorResponse(w, NewErrorResponse("Validation failed", ValidationError).WithDetails(errs), http.StatusBadRequest)
+}
+
 func WriteErrorResponse(w http.ResponseWriter, resp *ErrorResponse, status int) {
 	jsonStr, err := json.Marshal(resp)
 	if err != nil {
@@ -72,6 +103,12 @@ func NewErrorResponse(message string, code ErrorCode) *ErrorResponse {
 	}
 }
 
+// WithDetails attaches machine-readable context (e.g. a conflicting time window) to the error response.
+func (e *ErrorResponse) WithDetails(details interface{}) *ErrorResponse {
+	e.Details = details
+	return e
+}
+
 func WriteData(ctx context.Context, w http.ResponseWriter, response interface{}, httpStatus int) {
 	var toMarshal any = response
 	var err error
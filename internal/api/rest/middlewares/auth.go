@@ -1,31 +1,35 @@
 package middlewares
 
 import (
-	"context"
-	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"booking-service/internal/api/rest/helpers"
-	"github.com/golang-jwt/jwt/v5"
+	"booking-service/internal/store/sessions"
+	utoken "booking-service/pkg/utils/token"
+
+	"github.com/rs/zerolog/log"
 )
 
 const (
 	missingAuthHeader   = "Missing Authorization header"
 	invalidHeaderFormat = "Invalid Authorization header format"
 	invalidToken        = "Invalid or expired token"
-	invalidTokenClaims  = "Invalid token claims"
-	invalidTokenPayload = "Invalid token payload"
+	sessionEnded        = "Session has ended"
 )
 
 type JWTMiddleware struct {
-	jwtSecret string
+	tokens        *utoken.Service
+	sessionsStore sessions.Store
 }
 
-func NewJWTMiddleware(secret string) *JWTMiddleware {
-	return &JWTMiddleware{jwtSecret: secret}
+func NewJWTMiddleware(tokens *utoken.Service, sessionsStore sessions.Store) *JWTMiddleware {
+	return &JWTMiddleware{tokens: tokens, sessionsStore: sessionsStore}
 }
 
+// Middleware verifies the bearer token of the request and that its session was not revoked, and puts the
+// ID of its user into the context, where handlers get it with helpers.UserIDFromContext.
 func (m *JWTMiddleware) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
 		authHeader := req.Header.Get("Authorization")
@@ -41,33 +45,23 @@ func (m *JWTMiddleware) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
-		tokenString := parts[0]
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate the algorithm
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return m.jwtSecret, nil
-		})
-
+		claims, err := m.tokens.Verify(parts[1])
 		if err != nil {
 			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(invalidToken, helpers.InvalidTokenErr), http.StatusUnauthorized)
 			return
 		}
 
-		// Extract claims and put userID into context
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			userID, ok := claims["user_id"].(string)
-			if !ok {
-				helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(invalidTokenPayload, helpers.InvalidTokenErr), http.StatusUnauthorized)
-				return
-			}
-
-			ctx := context.WithValue(req.Context(), "userID", userID)
-			next.ServeHTTP(resp, req.WithContext(ctx))
-		} else {
-			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(invalidTokenClaims, helpers.InvalidTokenErr), http.StatusUnauthorized)
+		session, err := m.sessionsStore.GetSession(req.Context(), claims.SessionID)
+		if err != nil {
+			log.Ctx(req.Context()).Error().Err(err).Msgf("failed to get session %s", claims.SessionID)
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate session", helpers.InternalError), http.StatusInternalServerError)
+			return
+		}
+		if session == nil || session.UserID != claims.UserID || !session.Active(time.Now()) {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(sessionEnded, helpers.InvalidTokenErr), http.StatusUnauthorized)
+			return
 		}
+
+		next.ServeHTTP(resp, req.WithContext(helpers.WithUserID(req.Context(), claims.UserID)))
 	})
 }
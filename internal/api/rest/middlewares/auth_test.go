@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/sessions"
+	utoken "booking-service/pkg/utils/token"
+)
+
+type fakeSessions struct {
+	sessions.Store
+	sessions map[string]*sessions.Session
+}
+
+func (f *fakeSessions) GetSession(_ context.Context, id string) (*sessions.Session, error) {
+	return f.sessions[id], nil
+}
+
+func TestJWTMiddleware(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+	tokens := utoken.NewService("secret", time.Hour)
+	middleware := NewJWTMiddleware(tokens, &fakeSessions{sessions: map[string]*sessions.Session{
+		"active":  {ID: "active", UserID: "user-1", ExpiresAt: now.Add(time.Hour)},
+		"revoked": {ID: "revoked", UserID: "user-1", ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt},
+		"expired": {ID: "expired", UserID: "user-1", ExpiresAt: now.Add(-time.Minute)},
+	}})
+
+	tests := []struct {
+		name      string
+		userID    string
+		sessionID string
+		want      int
+	}{
+		{name: "active session", userID: "user-1", sessionID: "active", want: http.StatusOK},
+		{name: "revoked session", userID: "user-1", sessionID: "revoked", want: http.StatusUnauthorized},
+		{name: "expired session", userID: "user-1", sessionID: "expired", want: http.StatusUnauthorized},
+		{name: "unknown session", userID: "user-1", sessionID: "unknown", want: http.StatusUnauthorized},
+		{name: "session of another user", userID: "user-2", sessionID: "active", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tokens.Issue(tt.userID, tt.sessionID, now)
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp := httptest.NewRecorder()
+
+			var gotUserID string
+			middleware.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				gotUserID, _ = helpers.UserIDFromContext(req.Context())
+			})).ServeHTTP(resp, req)
+
+			if resp.Code != tt.want {
+				t.Fatalf("status = %d, want %d", resp.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && gotUserID != tt.userID {
+				t.Errorf("user in context = %q, want %q", gotUserID, tt.userID)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package schedules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/schedules"
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/business_accounts"
+	"booking-service/internal/store/services"
+	"booking-service/internal/store/specialists"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Handler struct {
+	servicesStore         services.Store
+	bookingsStore         bookings.Store
+	businessAccountsStore business_accounts.Store
+	specialistsStore      specialists.Store
+	slotStep              time.Duration
+}
+
+func NewHandler(servicesStore services.Store, bookingsStore bookings.Store, businessAccountsStore business_accounts.Store,
+	specialistsStore specialists.Store, slotStep time.Duration) *Handler {
+	return &Handler{
+		servicesStore:         servicesStore,
+		bookingsStore:         bookingsStore,
+		businessAccountsStore: businessAccountsStore,
+		specialistsStore:      specialistsStore,
+		slotStep:              slotStep,
+	}
+}
+
+type GetSchedulesResponse struct {
+	BusinessID   string           `json:"business_id"`
+	ServiceID    string           `json:"service_id"`
+	ServiceIDs   []string         `json:"service_ids,omitempty"`
+	SpecialistID *string          `json:"specialist_id,omitempty"`
+	Slots        []schedules.Slot `json:"slots"`
+}
+
+func (h *Handler) GetSchedules(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	query, err := parseGetSchedulesQueries(req.URL.Query(), h.slotStep)
+	if err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.InvalidQueries), http.StatusBadRequest)
+		return
+	}
+
+	booked := make([]*services.Service, 0, len(query.ServiceIDs))
+	for _, serviceID := range query.ServiceIDs {
+		service, err := h.servicesStore.GetService(ctx, serviceID)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to get service %s", serviceID)
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get service", helpers.InternalError), http.StatusInternalServerError)
+			return
+		}
+		if service == nil || !service.IsActive || service.BusinessAccountID != query.BusinessID {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Service not found", helpers.NotFound), http.StatusNotFound)
+			return
+		}
+		if len(query.ServiceIDs) > 1 && service.IsGroupClass() {
+			helpers.WriteErrorResponse(resp,
+				helpers.NewErrorResponse("group classes cannot be combined with other services", helpers.InvalidQueries), http.StatusBadRequest)
+			return
+		}
+		booked = append(booked, service)
+	}
+
+	var options []*services.Option
+	if len(query.VariantIDs) > 0 || len(query.AddOnIDs) > 0 {
+		options, err = h.servicesStore.ListOptions(ctx, query.ServiceIDs)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to list options of services %v", query.ServiceIDs)
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get service options", helpers.InternalError), http.StatusInternalServerError)
+			return
+		}
+	}
+	selections, err := services.Select(booked, options, query.VariantIDs, query.AddOnIDs)
+	if err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.InvalidQueries), http.StatusBadRequest)
+		return
+	}
+	performed := make([]*services.Service, 0, len(selections))
+	for _, selection := range selections {
+		performed = append(performed, selection.Performed())
+	}
+	// Several services are searched for as one appointment of their combined duration
+	service := services.Combine(performed)
+
+	hours, err := h.businessAccountsStore.GetWorkingHours(ctx, query.BusinessID)
+	if err != nil {
+		if errors.Is(err, business_accounts.NotFoundError) {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Business account not found", helpers.NotFound), http.StatusNotFound)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get working hours of business %s", query.BusinessID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get working hours", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	calendar, err := schedules.NewCalendar(hours)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("invalid working hours of business %s", query.BusinessID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid working hours", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	// Requested dates are calendar days of the business, not of the caller
+	from, to := calendar.Date(query.From), calendar.Date(query.To)
+
+	if query.SpecialistID != nil {
+		staff, ok := h.loadStaffHours(resp, req, *query.SpecialistID, query.BusinessID, query.ServiceIDs, from)
+		if !ok {
+			return
+		}
+		calendar = calendar.WithStaff(staff)
+	}
+	rules := schedules.SlotRules{
+		Duration:     time.Duration(service.DurationMinutes) * time.Minute,
+		Step:         query.Step,
+		Location:     calendar.Location,
+		BufferBefore: service.BufferBefore(),
+		BufferAfter:  service.BufferAfter(),
+		NotBefore:    service.EarliestStart(time.Now()),
+		NotAfter:     service.LatestStart(time.Now()),
+		Capacity:     service.Capacity,
+	}
+
+	// Bookings naming no specialist are assigned to one of those performing the services, so any of them being
+	// free makes a slot. Only businesses without such specialists and group classes are booked unassigned.
+	var qualified []*specialists.Specialist
+	if query.SpecialistID == nil && !service.IsGroupClass() {
+		qualified, err = h.specialistsStore.ListQualified(ctx, query.BusinessID, query.ServiceIDs)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to list specialists of business %s", query.BusinessID)
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialists", helpers.InternalError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var slots []schedules.Slot
+	if len(qualified) > 0 {
+		slots, err = h.listQualifiedSlots(ctx, calendar, qualified, rules, from, to)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to get free slots of specialists of business %s", query.BusinessID)
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get free slots", helpers.InternalError), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		// Buffers may reach past the requested days, so bookings just outside of them matter too
+		busyWindows, err := h.bookingsStore.ListBusyWindows(ctx, query.BusinessID, query.SpecialistID,
+			from.Add(-rules.BufferBefore), to.Add(rules.BufferAfter))
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to list bookings of business %s", query.BusinessID)
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get bookings", helpers.InternalError), http.StatusInternalServerError)
+			return
+		}
+		busy := busyIntervals(busyWindows)
+
+		open := calendar.Expand(from, to)
+		slots = schedules.Slots(open, busy, rules)
+		if service.IsGroupClass() {
+			sessions, err := h.listOpenSessions(ctx, service, query.SpecialistID, from, to)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msgf("failed to list class sessions of business %s", query.BusinessID)
+				helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get class sessions", helpers.InternalError), http.StatusInternalServerError)
+				return
+			}
+			slots = schedules.ClassSlots(open, busy, sessions, rules)
+		}
+	}
+
+	helpers.WriteData(ctx, resp, GetSchedulesResponse{
+		BusinessID:   query.BusinessID,
+		ServiceID:    query.ServiceID,
+		ServiceIDs:   multipleServices(query.ServiceIDs),
+		SpecialistID: query.SpecialistID,
+		Slots:        slots,
+	}, http.StatusOK)
+}
+
+// loadStaffHours checks that the specialist works for the business and performs the services, and returns
+// their working time from the given day on. Error responses are written here.
+func (h *Handler) loadStaffHours(resp http.ResponseWriter, req *http.Request, specialistID, businessID string,
+	serviceIDs []string, from time.Time) (*schedules.StaffHours, bool) {
+	ctx := req.Context()
+
+	specialist, err := h.specialistsStore.GetSpecialist(ctx, specialistID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get specialist %s", specialistID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialist", helpers.InternalError), http.StatusInternalServerError)
+		return nil, false
+	}
+	if specialist == nil || specialist.BusinessAccountID != businessID {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Specialist not found", helpers.NotFound), http.StatusNotFound)
+		return nil, false
+	}
+
+	for _, serviceID := range serviceIDs {
+		performs, err := h.specialistsStore.CanPerform(ctx, specialistID, serviceID)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to get services of specialist %s", specialistID)
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialist services", helpers.InternalError), http.StatusInternalServerError)
+			return nil, false
+		}
+		if !performs {
+			helpers.WriteErrorResponse(resp,
+				helpers.NewErrorResponse(fmt.Sprintf("the specialist does not perform service %s", serviceID), helpers.InvalidQueries), http.StatusBadRequest)
+			return nil, false
+		}
+	}
+
+	schedule, err := h.specialistsStore.GetSchedule(ctx, specialistID, from)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get schedule of specialist %s", specialistID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialist schedule", helpers.InternalError), http.StatusInternalServerError)
+		return nil, false
+	}
+	staff, err := schedules.NewStaffHours(schedule)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("invalid schedule of specialist %s", specialistID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid specialist schedule", helpers.InternalError), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return staff, true
+}
+
+// listQualifiedSlots returns the slots any of the specialists is free for: each of them within their own working
+// time and clear of their own bookings. Schedules and bookings of all specialists are loaded at once.
+func (h *Handler) listQualifiedSlots(ctx context.Context, calendar *schedules.Calendar, qualified []*specialists.Specialist,
+	rules schedules.SlotRules, from, to time.Time) ([]schedules.Slot, error) {
+	ids := make([]string, 0, len(qualified))
+	for _, specialist := range qualified {
+		ids = append(ids, specialist.ID)
+	}
+
+	staffSchedules, err := h.specialistsStore.ListSchedules(ctx, ids, from)
+	if err != nil {
+		return nil, err
+	}
+	busyWindows, err := h.bookingsStore.ListSpecialistBusyWindows(ctx, ids, from.Add(-rules.BufferBefore), to.Add(rules.BufferAfter))
+	if err != nil {
+		return nil, err
+	}
+
+	slotSets := make([][]schedules.Slot, 0, len(ids))
+	for _, id := range ids {
+		staff, err := schedules.NewStaffHours(staffSchedules[id])
+		if err != nil {
+			return nil, err
+		}
+		open := calendar.WithStaff(staff).Expand(from, to)
+		slotSets = append(slotSets, schedules.Slots(open, busyIntervals(busyWindows[id]), rules))
+	}
+	return schedules.UnionSlots(slotSets...), nil
+}
+
+// listOpenSessions returns the sessions of the class with the specialist that have attendees already.
+// Sessions nobody attends anymore do not block their time and are offered as free slots.
+func (h *Handler) listOpenSessions(ctx context.Context, service *services.Service, specialistID *string,
+	from, to time.Time) ([]schedules.Session, error) {
+	classSessions, err := h.bookingsStore.ListSessions(ctx, service.BusinessAccountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []schedules.Session
+	for _, session := range classSessions {
+		if session.ServiceID != service.ID || session.Booked == 0 || !sameSpecialist(session.SpecialistID, specialistID) {
+			continue
+		}
+		sessions = append(sessions, schedules.Session{
+			Interval:  schedules.Interval{Start: session.StartTime, End: session.EndTime},
+			SpotsLeft: session.SpotsLeft(),
+		})
+	}
+	return sessions, nil
+}
+
+// multipleServices returns the ids of a multi-service search, nil for a single service.
+func multipleServices(ids []string) []string {
+	if len(ids) < 2 {
+		return nil
+	}
+	return ids
+}
+
+func busyIntervals(windows []bookings.TimeWindow) []schedules.Interval {
+	busy := make([]schedules.Interval, 0, len(windows))
+	for _, w := range windows {
+		busy = append(busy, schedules.Interval{Start: w.StartTime, End: w.EndTime})
+	}
+	return busy
+}
+
+func sameSpecialist(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,116 @@
+package schedules
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/business_accounts"
+	"booking-service/internal/store/services"
+	"booking-service/internal/store/specialists"
+)
+
+type fakeServices struct {
+	services.Store
+}
+
+func (f *fakeServices) GetService(_ context.Context, id string) (*services.Service, error) {
+	return &services.Service{ID: id, BusinessAccountID: "salon", DurationMinutes: 60, IsActive: true, Capacity: 1}, nil
+}
+
+type fakeBusinessAccounts struct {
+	business_accounts.Store
+}
+
+func (f *fakeBusinessAccounts) GetWorkingHours(context.Context, string) (*business_accounts.WorkingHours, error) {
+	return &business_accounts.WorkingHours{Timezone: "UTC", Weekly: []business_accounts.WorkingInterval{
+		{Weekday: time.Monday, TimeInterval: business_accounts.TimeInterval{StartTime: "09:00", EndTime: "12:00"}},
+	}}, nil
+}
+
+// fakeSpecialists has the given specialists performing every service, all working the hours of the business.
+type fakeSpecialists struct {
+	specialists.Store
+	ids []string
+}
+
+func (f *fakeSpecialists) ListQualified(context.Context, string, []string) ([]*specialists.Specialist, error) {
+	qualified := make([]*specialists.Specialist, 0, len(f.ids))
+	for _, id := range f.ids {
+		qualified = append(qualified, &specialists.Specialist{ID: id, BusinessAccountID: "salon"})
+	}
+	return qualified, nil
+}
+
+func (f *fakeSpecialists) ListSchedules(_ context.Context, ids []string, _ time.Time) (map[string]*specialists.Schedule, error) {
+	schedules := make(map[string]*specialists.Schedule, len(ids))
+	for _, id := range ids {
+		schedules[id] = &specialists.Schedule{SpecialistID: id}
+	}
+	return schedules, nil
+}
+
+type fakeBookings struct {
+	bookings.Store
+	unassigned   []bookings.TimeWindow
+	bySpecialist map[string][]bookings.TimeWindow
+}
+
+func (f *fakeBookings) ListBusyWindows(context.Context, string, *string, time.Time, time.Time) ([]bookings.TimeWindow, error) {
+	return f.unassigned, nil
+}
+
+func (f *fakeBookings) ListSpecialistBusyWindows(context.Context, []string, time.Time, time.Time) (map[string][]bookings.TimeWindow, error) {
+	return f.bySpecialist, nil
+}
+
+func TestHandler_GetSchedules_AnySpecialist(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2030, time.March, 4, hour, 0, 0, 0, time.UTC) }
+	tenOClock := []bookings.TimeWindow{{StartTime: at(10), EndTime: at(11)}}
+
+	tests := []struct {
+		name        string
+		specialists []string
+		bookings    *fakeBookings
+		want        map[int]int
+	}{
+		{name: "business without specialists", bookings: &fakeBookings{unassigned: tenOClock},
+			want: map[int]int{9: 1, 11: 1}},
+		{name: "any specialist free", specialists: []string{"jane", "john"},
+			bookings: &fakeBookings{unassigned: tenOClock, bySpecialist: map[string][]bookings.TimeWindow{"john": tenOClock}},
+			want:     map[int]int{9: 2, 10: 1, 11: 2}},
+		{name: "every specialist busy", specialists: []string{"jane"},
+			bookings: &fakeBookings{bySpecialist: map[string][]bookings.TimeWindow{"jane": tenOClock}},
+			want:     map[int]int{9: 1, 11: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&fakeServices{}, tt.bookings, &fakeBusinessAccounts{}, &fakeSpecialists{ids: tt.specialists}, time.Hour)
+			req := httptest.NewRequest(http.MethodGet, "/?business_id=salon&service_id=cut&from=2030-03-04&to=2030-03-04", nil)
+			resp := httptest.NewRecorder()
+
+			h.GetSchedules(resp, req)
+
+			if resp.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", resp.Code, http.StatusOK, resp.Body)
+			}
+			var got GetSchedulesResponse
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Slots) != len(tt.want) {
+				t.Fatalf("slots = %v, want starts and spots %v", got.Slots, tt.want)
+			}
+			for _, slot := range got.Slots {
+				if spots, ok := tt.want[slot.StartTime.Hour()]; !ok || slot.SpotsLeft != spots {
+					t.Errorf("slot at %s has %d spots, want %v", slot.StartTime, slot.SpotsLeft, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package schedules
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type Router struct {
+	handler        *Handler
+	authMiddleware mux.MiddlewareFunc
+}
+
+func NewRouter(handler *Handler, authMiddleware mux.MiddlewareFunc) Router {
+	return Router{handler: handler, authMiddleware: authMiddleware}
+}
+
+func (r Router) RegisterRoutes(router *mux.Router) {
+	schedulesRouter := router.PathPrefix("/schedules").Subrouter()
+	schedulesRouter.Use(r.authMiddleware.Middleware)
+
+	schedulesRouter.HandleFunc("", r.handler.GetSchedules).Methods(http.MethodGet)
+}
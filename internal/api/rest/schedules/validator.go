@@ -0,0 +1,122 @@
+package schedules
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	BusinessID   = "business_id"
+	ServiceID    = "service_id"
+	ServiceIDs   = "service_ids"
+	VariantIDs   = "variant_ids"
+	AddOnIDs     = "add_on_ids"
+	SpecialistID = "specialist_id"
+	From         = "from"
+	To           = "to"
+	StepMinutes  = "step_minutes"
+
+	dateLayout   = "2006-01-02"
+	maxRangeDays = 31
+	// maxServices caps the services searched for as one appointment, like for bookings.
+	maxServices = 5
+)
+
+type getSchedulesQuery struct {
+	BusinessID string
+	ServiceID  string
+	// ServiceIDs are the services performed back to back, ServiceID is the first of them.
+	ServiceIDs []string
+	// VariantIDs and AddOnIDs pick options of the services, which change the duration searched for.
+	VariantIDs   []string
+	AddOnIDs     []string
+	SpecialistID *string
+	From         time.Time
+	To           time.Time
+	Step         time.Duration
+}
+
+// parseGetSchedulesQueries validates the query of GET /schedules. Dates are inclusive, so to=from
+// returns the slots of a single day.
+func parseGetSchedulesQueries(queries url.Values, defaultStep time.Duration) (*getSchedulesQuery, error) {
+	query := &getSchedulesQuery{
+		BusinessID: queries.Get(BusinessID),
+		ServiceID:  queries.Get(ServiceID),
+		Step:       defaultStep,
+	}
+
+	if query.BusinessID == "" {
+		return nil, errors.New("business_id is required")
+	}
+	if ids := queries.Get(ServiceIDs); ids != "" {
+		query.ServiceIDs = strings.Split(ids, ",")
+		if slices.Contains(query.ServiceIDs, "") {
+			return nil, errors.New("service_ids must be a comma-separated list of ids")
+		}
+		if len(query.ServiceIDs) > maxServices {
+			return nil, fmt.Errorf("at most %d services can be booked together", maxServices)
+		}
+		query.ServiceID = query.ServiceIDs[0]
+	}
+	if query.ServiceID == "" {
+		return nil, errors.New("service_id is required")
+	}
+	if len(query.ServiceIDs) == 0 {
+		query.ServiceIDs = []string{query.ServiceID}
+	}
+	if specialistID := queries.Get(SpecialistID); specialistID != "" {
+		query.SpecialistID = &specialistID
+	}
+
+	from, err := time.Parse(dateLayout, queries.Get(From))
+	if err != nil {
+		return nil, errors.New("from must be a date in YYYY-MM-DD format")
+	}
+	to, err := time.Parse(dateLayout, queries.Get(To))
+	if err != nil {
+		return nil, errors.New("to must be a date in YYYY-MM-DD format")
+	}
+	if to.Before(from) {
+		return nil, errors.New("to must not be before from")
+	}
+	if to.Sub(from) >= maxRangeDays*24*time.Hour {
+		return nil, errors.New("date range cannot exceed 31 days")
+	}
+	query.From = from
+	query.To = to.AddDate(0, 0, 1)
+
+	if stepStr := queries.Get(StepMinutes); stepStr != "" {
+		step, err := strconv.Atoi(stepStr)
+		if err != nil || step <= 0 {
+			return nil, errors.New("step_minutes must be a positive integer")
+		}
+		query.Step = time.Duration(step) * time.Minute
+	}
+
+	if query.VariantIDs, err = parseIDs(queries, VariantIDs); err != nil {
+		return nil, err
+	}
+	if query.AddOnIDs, err = parseIDs(queries, AddOnIDs); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+// parseIDs reads an optional comma-separated list of ids.
+func parseIDs(queries url.Values, param string) ([]string, error) {
+	list := queries.Get(param)
+	if list == "" {
+		return nil, nil
+	}
+	ids := strings.Split(list, ",")
+	if slices.Contains(ids, "") {
+		return nil, fmt.Errorf("%s must be a comma-separated list of ids", param)
+	}
+	return ids, nil
+}
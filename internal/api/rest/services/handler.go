@@ -2,25 +2,31 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"booking-service/internal/api/rest/helpers"
 	"booking-service/internal/store/business_accounts"
 	"booking-service/internal/store/services"
+	"booking-service/internal/store/taxonomy"
+	"booking-service/pkg/money"
 
 	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
 )
 
 type Handler struct {
 	servicesStore         services.Store
 	businessAccountsStore business_accounts.Store
+	taxonomyStore         taxonomy.Store
 }
 
-func NewHandler(servicesStore services.Store, businessAccountsStore business_accounts.Store) *Handler {
+func NewHandler(servicesStore services.Store, businessAccountsStore business_accounts.Store, taxonomyStore taxonomy.Store) *Handler {
 	return &Handler{
 		servicesStore:         servicesStore,
 		businessAccountsStore: businessAccountsStore,
+		taxonomyStore:         taxonomyStore,
 	}
 }
 
@@ -44,10 +50,7 @@ func (h *Handler) CreateService(resp http.ResponseWriter, req *http.Request) {
 		)
 		return
 	}
-
 	// Verify business account exists and user owns it
-	// Note: In a real implementation, you'd get the user ID from JWT token
-	// For now, we'll assume the business account ID is valid
 	_, err := h.businessAccountsStore.GetBusinessAccount(req.Context(), createReq.BusinessAccountID)
 	if err != nil {
 		helpers.WriteErrorResponse(
@@ -57,6 +60,12 @@ func (h *Handler) CreateService(resp http.ResponseWriter, req *http.Request) {
 		)
 		return
 	}
+	if !h.authorizeBusinessAccount(resp, req, createReq.BusinessAccountID) {
+		return
+	}
+	if !h.checkCategory(resp, req, createReq.Category) {
+		return
+	}
 
 	service, err := h.servicesStore.CreateService(req.Context(), createReq)
 	if err != nil {
@@ -112,6 +121,23 @@ func (h *Handler) GetService(resp http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	options, err := h.servicesStore.ListOptions(req.Context(), []string{service.ID})
+	if err != nil {
+		helpers.WriteErrorResponse(
+			resp,
+			helpers.NewErrorResponse("Failed to get service options", helpers.InternalError),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+	for _, option := range options {
+		if option.Kind == services.OptionVariant {
+			service.Variants = append(service.Variants, option)
+		} else {
+			service.AddOns = append(service.AddOns, option)
+		}
+	}
+
 	resp.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(resp).Encode(service); err != nil {
 		helpers.WriteErrorResponse(
@@ -155,31 +181,23 @@ func (h *Handler) UpdateService(resp http.ResponseWriter, req *http.Request) {
 		)
 		return
 	}
-
 	// Verify service exists and user owns it
-	existingService, err := h.servicesStore.GetService(req.Context(), serviceID)
-	if err != nil {
-		helpers.WriteErrorResponse(
-			resp,
-			helpers.NewErrorResponse("Failed to get service", helpers.InternalError),
-			http.StatusInternalServerError,
-		)
+	if _, ok := h.authorizeService(resp, req); !ok {
+		return
+	}
+	if !h.checkCategory(resp, req, updateReq.Category) {
 		return
 	}
 
-	if existingService == nil {
+	service, err := h.servicesStore.UpdateService(req.Context(), serviceID, updateReq)
+	if errors.Is(err, services.ErrCurrencyLocked) {
 		helpers.WriteErrorResponse(
 			resp,
-			helpers.NewErrorResponse("Service not found", helpers.NotFound),
-			http.StatusNotFound,
+			helpers.NewErrorResponse(err.Error(), helpers.Conflict),
+			http.StatusConflict,
 		)
 		return
 	}
-
-	// Note: In a real implementation, you'd verify the user owns this service
-	// by checking the business account ownership
-
-	service, err := h.servicesStore.UpdateService(req.Context(), serviceID, updateReq)
 	if err != nil {
 		helpers.WriteErrorResponse(
 			resp,
@@ -214,29 +232,11 @@ func (h *Handler) DeleteService(resp http.ResponseWriter, req *http.Request) {
 	}
 
 	// Verify service exists and user owns it
-	existingService, err := h.servicesStore.GetService(req.Context(), serviceID)
-	if err != nil {
-		helpers.WriteErrorResponse(
-			resp,
-			helpers.NewErrorResponse("Failed to get service", helpers.InternalError),
-			http.StatusInternalServerError,
-		)
+	if _, ok := h.authorizeService(resp, req); !ok {
 		return
 	}
 
-	if existingService == nil {
-		helpers.WriteErrorResponse(
-			resp,
-			helpers.NewErrorResponse("Service not found", helpers.NotFound),
-			http.StatusNotFound,
-		)
-		return
-	}
-
-	// Note: In a real implementation, you'd verify the user owns this service
-	// by checking the business account ownership
-
-	err = h.servicesStore.DeleteService(req.Context(), serviceID)
+	err := h.servicesStore.DeleteService(req.Context(), serviceID)
 	if err != nil {
 		helpers.WriteErrorResponse(
 			resp,
@@ -372,13 +372,13 @@ func validateCreateServiceRequest(req services.CreateServiceRequest) error {
 	if req.DurationMinutes <= 0 {
 		return helpers.NewValidationError("duration_minutes must be greater than 0")
 	}
-	if req.Price < 0 {
-		return helpers.NewValidationError("price cannot be negative")
+	if err := validatePrice(req.Price); err != nil {
+		return err
 	}
-	if req.Currency != "" && len(req.Currency) != 3 {
-		return helpers.NewValidationError("currency must be a 3-character code")
+	if req.Capacity < 0 {
+		return helpers.NewValidationError("capacity must be at least 1")
 	}
-	return nil
+	return validateBookingRules(&req.BufferBeforeMinutes, &req.BufferAfterMinutes, &req.MinLeadMinutes, &req.MaxHorizonDays)
 }
 
 func validateUpdateServiceRequest(req services.UpdateServiceRequest) error {
@@ -388,11 +388,96 @@ func validateUpdateServiceRequest(req services.UpdateServiceRequest) error {
 	if req.DurationMinutes != nil && *req.DurationMinutes <= 0 {
 		return helpers.NewValidationError("duration_minutes must be greater than 0")
 	}
-	if req.Price != nil && *req.Price < 0 {
+	if req.Price != nil {
+		if req.Price.Amount == nil {
+			return helpers.NewValidationError("price amount is required")
+		}
+		if err := validatePrice(money.New(*req.Price.Amount, req.Price.Currency)); err != nil {
+			return err
+		}
+	}
+	if req.Capacity != nil && *req.Capacity < 1 {
+		return helpers.NewValidationError("capacity must be at least 1")
+	}
+	return validateBookingRules(req.BufferBeforeMinutes, req.BufferAfterMinutes, req.MinLeadMinutes, req.MaxHorizonDays)
+}
+
+// authorizeBusinessAccount makes sure the caller owns the business account. Error responses are written here.
+func (h *Handler) authorizeBusinessAccount(resp http.ResponseWriter, req *http.Request, businessAccountID string) bool {
+	ctx := req.Context()
+
+	userID, ok := helpers.UserIDFromContext(ctx)
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return false
+	}
+
+	owns, err := h.businessAccountsStore.UserOwnsBusinessAccount(ctx, businessAccountID, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to validate ownership of business account %s", businessAccountID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate ownership", helpers.InternalError), http.StatusInternalServerError)
+		return false
+	}
+	if !owns {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Forbidden: you do not own this business account", helpers.Forbidden), http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// checkCategory makes sure a service category is a category or sub-category of the taxonomy.
+// Error responses are written here.
+func (h *Handler) checkCategory(resp http.ResponseWriter, req *http.Request, slug *string) bool {
+	if slug == nil {
+		return true
+	}
+
+	category, err := h.taxonomyStore.GetCategory(req.Context(), *slug)
+	if err != nil {
+		helpers.WriteErrorResponse(
+			resp,
+			helpers.NewErrorResponse("Failed to get category", helpers.InternalError),
+			http.StatusInternalServerError,
+		)
+		return false
+	}
+	if category == nil || category.Level == taxonomy.LevelArea {
+		helpers.WriteErrorResponse(
+			resp,
+			helpers.NewErrorResponse("category must be a category or sub-category slug", helpers.ValidationError),
+			http.StatusBadRequest,
+		)
+		return false
+	}
+
+	return true
+}
+
+// validatePrice checks an amount in minor units of its currency. The currency may be left out.
+func validatePrice(price money.Money) error {
+	if price.Amount < 0 {
 		return helpers.NewValidationError("price cannot be negative")
 	}
-	if req.Currency != nil && len(*req.Currency) != 3 {
-		return helpers.NewValidationError("currency must be a 3-character code")
+	if price.Currency != "" && !price.Currency.Valid() {
+		return helpers.NewValidationError("price currency must be an ISO 4217 currency code")
+	}
+	return nil
+}
+
+// validateBookingRules checks the booking rules of a service; nil rules are left unchanged by an update.
+func validateBookingRules(bufferBefore, bufferAfter, minLead, maxHorizon *int) error {
+	if bufferBefore != nil && *bufferBefore < 0 {
+		return helpers.NewValidationError("buffer_before_minutes cannot be negative")
+	}
+	if bufferAfter != nil && *bufferAfter < 0 {
+		return helpers.NewValidationError("buffer_after_minutes cannot be negative")
+	}
+	if minLead != nil && *minLead < 0 {
+		return helpers.NewValidationError("min_lead_minutes cannot be negative")
+	}
+	if maxHorizon != nil && *maxHorizon < 0 {
+		return helpers.NewValidationError("max_horizon_days cannot be negative")
 	}
 	return nil
 }
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/services"
+	"booking-service/pkg/money"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+type ListOptionsResponse struct {
+	Options []*services.Option `json:"options"`
+}
+
+func (h *Handler) ListVariants(resp http.ResponseWriter, req *http.Request) {
+	h.listOptions(resp, req, services.OptionVariant)
+}
+
+func (h *Handler) CreateVariant(resp http.ResponseWriter, req *http.Request) {
+	h.createOption(resp, req, services.OptionVariant)
+}
+
+func (h *Handler) UpdateVariant(resp http.ResponseWriter, req *http.Request) {
+	h.updateOption(resp, req, services.OptionVariant)
+}
+
+func (h *Handler) DeleteVariant(resp http.ResponseWriter, req *http.Request) {
+	h.deleteOption(resp, req, services.OptionVariant)
+}
+
+func (h *Handler) ListAddOns(resp http.ResponseWriter, req *http.Request) {
+	h.listOptions(resp, req, services.OptionAddOn)
+}
+
+func (h *Handler) CreateAddOn(resp http.ResponseWriter, req *http.Request) {
+	h.createOption(resp, req, services.OptionAddOn)
+}
+
+func (h *Handler) UpdateAddOn(resp http.ResponseWriter, req *http.Request) {
+	h.updateOption(resp, req, services.OptionAddOn)
+}
+
+func (h *Handler) DeleteAddOn(resp http.ResponseWriter, req *http.Request) {
+	h.deleteOption(resp, req, services.OptionAddOn)
+}
+
+func (h *Handler) listOptions(resp http.ResponseWriter, req *http.Request, kind services.OptionKind) {
+	ctx := req.Context()
+	serviceID := mux.Vars(req)["id"]
+
+	service, err := h.servicesStore.GetService(ctx, serviceID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get service %s", serviceID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get service", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	if service == nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Service not found", helpers.NotFound), http.StatusNotFound)
+		return
+	}
+
+	options, err := h.servicesStore.ListOptions(ctx, []string{service.ID})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to list options of service %s", service.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get service options", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	ofKind := []*services.Option{}
+	for _, option := range options {
+		if option.Kind == kind {
+			ofKind = append(ofKind, option)
+		}
+	}
+
+	helpers.WriteData(ctx, resp, ListOptionsResponse{Options: ofKind}, http.StatusOK)
+}
+
+func (h *Handler) createOption(resp http.ResponseWriter, req *http.Request, kind services.OptionKind) {
+	ctx := req.Context()
+
+	service, ok := h.authorizeService(resp, req)
+	if !ok {
+		return
+	}
+
+	var createReq services.CreateOptionRequest
+	if err := json.NewDecoder(req.Body).Decode(&createReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+
+	if errs := validateOption(kind, service.Price.Currency, &createReq.Name, &createReq.DurationMinutes, &createReq.Price); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	option, err := h.servicesStore.CreateOption(ctx, service.ID, kind, createReq)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to create option of service %s", service.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to create service option", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteData(ctx, resp, option, http.StatusCreated)
+}
+
+func (h *Handler) updateOption(resp http.ResponseWriter, req *http.Request, kind services.OptionKind) {
+	ctx := req.Context()
+
+	option, ok := h.authorizeOption(resp, req, kind)
+	if !ok {
+		return
+	}
+
+	var updateReq services.UpdateOptionRequest
+	if err := json.NewDecoder(req.Body).Decode(&updateReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+
+	if errs := validateOption(kind, option.Price.Currency, updateReq.Name, updateReq.DurationMinutes, updateReq.Price); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	updated, err := h.servicesStore.UpdateOption(ctx, option.ID, updateReq)
+	if err != nil {
+		if errors.Is(err, services.ErrOptionNotFound) {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Service option not found", helpers.NotFound), http.StatusNotFound)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to update service option %s", option.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to update service option", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteData(ctx, resp, updated, http.StatusOK)
+}
+
+// deleteOption removes the option from the service. Bookings made with it keep their price and duration.
+func (h *Handler) deleteOption(resp http.ResponseWriter, req *http.Request, kind services.OptionKind) {
+	ctx := req.Context()
+
+	option, ok := h.authorizeOption(resp, req, kind)
+	if !ok {
+		return
+	}
+
+	if err := h.servicesStore.DeleteOption(ctx, option.ID); err != nil {
+		if errors.Is(err, services.ErrOptionNotFound) {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Service option not found", helpers.NotFound), http.StatusNotFound)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to delete service option %s", option.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to delete service option", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeService loads the service from the path and makes sure the caller owns its business account.
+// Error responses are written here.
+func (h *Handler) authorizeService(resp http.ResponseWriter, req *http.Request) (*services.Service, bool) {
+	ctx := req.Context()
+	serviceID := mux.Vars(req)["id"]
+
+	service, err := h.servicesStore.GetService(ctx, serviceID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get service %s", serviceID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get service", helpers.InternalError), http.StatusInternalServerError)
+		return nil, false
+	}
+	if service == nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Service not found", helpers.NotFound), http.StatusNotFound)
+		return nil, false
+	}
+
+	if !h.authorizeBusinessAccount(resp, req, service.BusinessAccountID) {
+		return nil, false
+	}
+
+	return service, true
+}
+
+// authorizeOption loads the option of the kind from the path, making sure it belongs to the service of the
+// path and that the caller owns the service. Error responses are written here.
+func (h *Handler) authorizeOption(resp http.ResponseWriter, req *http.Request, kind services.OptionKind) (*services.Option, bool) {
+	ctx := req.Context()
+	optionID := mux.Vars(req)["option_id"]
+
+	service, ok := h.authorizeService(resp, req)
+	if !ok {
+		return nil, false
+	}
+
+	option, err := h.servicesStore.GetOption(ctx, optionID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get service option %s", optionID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get service option", helpers.InternalError), http.StatusInternalServerError)
+		return nil, false
+	}
+	if option == nil || option.ServiceID != service.ID || option.Kind != kind {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Service option not found", helpers.NotFound), http.StatusNotFound)
+		return nil, false
+	}
+
+	return option, true
+}
+
+// validateOption checks the set fields of a variant or add-on. A variant replaces the duration of its
+// service, so it must take some time; an add-on may take none. Prices are in the currency of the service.
+func validateOption(kind services.OptionKind, currency money.Currency, name *string, durationMinutes *int,
+	price *money.Money) helpers.FieldErrors {
+	var errs helpers.FieldErrors
+	if name != nil && *name == "" {
+		errs.Add("name", "name is required")
+	}
+	if durationMinutes != nil {
+		if kind == services.OptionVariant && *durationMinutes <= 0 {
+			errs.Add("duration_minutes", "duration_minutes must be greater than 0")
+		}
+		if kind == services.OptionAddOn && *durationMinutes < 0 {
+			errs.Add("duration_minutes", "duration_minutes must not be negative")
+		}
+	}
+	if price != nil {
+		if price.Amount < 0 {
+			errs.Add("price", "price must not be negative")
+		}
+		if price.Currency != "" && price.Currency != currency {
+			errs.Add("price", fmt.Sprintf("price must be in %s, the currency of the service", currency))
+		}
+	}
+	return errs
+}
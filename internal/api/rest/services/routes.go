@@ -26,6 +26,16 @@ func (r Router) RegisterRoutes(router *mux.Router) {
 	servicesRouter.HandleFunc("/{id}", r.handler.UpdateService).Methods(http.MethodPut)
 	servicesRouter.HandleFunc("/{id}", r.handler.DeleteService).Methods(http.MethodDelete)
 
+	// Variants and add-ons of a service
+	servicesRouter.HandleFunc("/{id}/variants", r.handler.ListVariants).Methods(http.MethodGet)
+	servicesRouter.HandleFunc("/{id}/variants", r.handler.CreateVariant).Methods(http.MethodPost)
+	servicesRouter.HandleFunc("/{id}/variants/{option_id}", r.handler.UpdateVariant).Methods(http.MethodPut)
+	servicesRouter.HandleFunc("/{id}/variants/{option_id}", r.handler.DeleteVariant).Methods(http.MethodDelete)
+	servicesRouter.HandleFunc("/{id}/add-ons", r.handler.ListAddOns).Methods(http.MethodGet)
+	servicesRouter.HandleFunc("/{id}/add-ons", r.handler.CreateAddOn).Methods(http.MethodPost)
+	servicesRouter.HandleFunc("/{id}/add-ons/{option_id}", r.handler.UpdateAddOn).Methods(http.MethodPut)
+	servicesRouter.HandleFunc("/{id}/add-ons/{option_id}", r.handler.DeleteAddOn).Methods(http.MethodDelete)
+
 	// Get services by business account
 	servicesRouter.HandleFunc("/business-account/{business_account_id}", r.handler.GetServicesByBusinessAccount).Methods(http.MethodGet)
 }
@@ -1,27 +1,351 @@
 package specialists
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/business_accounts"
+	"booking-service/internal/store/services"
+	"booking-service/internal/store/specialists"
+	"booking-service/internal/store/taxonomy"
+
+	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 )
 
 type Handler struct {
+	store                 specialists.Store
+	businessAccountsStore business_accounts.Store
+	servicesStore         services.Store
+	bookingsStore         bookings.Store
+	taxonomyStore         taxonomy.Store
+	slotStep              time.Duration
+}
+
+func NewHandler(store specialists.Store, businessAccountsStore business_accounts.Store, servicesStore services.Store,
+	bookingsStore bookings.Store, taxonomyStore taxonomy.Store, slotStep time.Duration) *Handler {
+	return &Handler{
+		store:                 store,
+		businessAccountsStore: businessAccountsStore,
+		servicesStore:         servicesStore,
+		bookingsStore:         bookingsStore,
+		taxonomyStore:         taxonomyStore,
+		slotStep:              slotStep,
+	}
+}
+
+type SetServicesRequest struct {
+	ServiceIDs []string `json:"service_ids"`
+}
+
+// CreateSpecialist adds a staff profile to a business account of the caller.
+func (h *Handler) CreateSpecialist(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var createReq specialists.CreateSpecialistRequest
+	if err := json.NewDecoder(req.Body).Decode(&createReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+
+	if errs := validateCreateSpecialistRequest(createReq); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+	if !h.authorizeBusiness(resp, req, createReq.BusinessAccountID) {
+		return
+	}
+	if !h.checkArea(resp, req, &createReq.AreaType) {
+		return
+	}
+
+	specialist, err := h.store.CreateSpecialist(ctx, createReq)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to create specialist")
+		return
+	}
+
+	helpers.WriteData(ctx, resp, specialist, http.StatusCreated)
+}
+
+// GetSpecialist returns the profile with the services the specialist performs.
+func (h *Handler) GetSpecialist(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	specialistID := mux.Vars(req)["id"]
+
+	specialist, err := h.store.GetSpecialist(ctx, specialistID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get specialist %s", specialistID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialist", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	if specialist == nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Specialist not found", helpers.NotFound), http.StatusNotFound)
+		return
+	}
+
+	specialist.ServiceIDs, err = h.store.ListServiceIDs(ctx, specialist.ID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to list services of specialist %s", specialist.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialist services", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteData(ctx, resp, specialist, http.StatusOK)
+}
+
+func (h *Handler) UpdateSpecialist(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	specialist, ok := h.authorizeSpecialist(resp, req)
+	if !ok {
+		return
+	}
+
+	var updateReq specialists.UpdateSpecialistRequest
+	if err := json.NewDecoder(req.Body).Decode(&updateReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+
+	if errs := validateUpdateSpecialistRequest(updateReq); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+	if !h.checkArea(resp, req, updateReq.AreaType) {
+		return
+	}
+
+	updated, err := h.store.UpdateSpecialist(ctx, specialist.ID, updateReq)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to update specialist")
+		return
+	}
+
+	helpers.WriteData(ctx, resp, updated, http.StatusOK)
 }
 
-func NewHandler() *Handler {
-	return &Handler{}
+// AcceptLink links the profile to the user account the owner invited, once the user accepts.
+func (h *Handler) AcceptLink(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	specialistID := mux.Vars(req)["id"]
+
+	userID, ok := helpers.UserIDFromContext(ctx)
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return
+	}
+
+	// Profiles the caller was not invited to are reported as missing
+	specialist, err := h.store.AcceptLink(ctx, specialistID, userID)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to link specialist")
+		return
+	}
+
+	helpers.WriteData(ctx, resp, specialist, http.StatusOK)
 }
 
-func (h *Handler) GetSpecialists(resp http.ResponseWriter, req *http.Request) {
-	queries := req.URL.Query()
+// Unlink removes the user account from the profile. The owner and the invited or linked user may do it.
+func (h *Handler) Unlink(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	specialistID := mux.Vars(req)["id"]
+
+	userID, ok := helpers.UserIDFromContext(ctx)
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return
+	}
 
-	areaType, city, err := validateGetSpecialistsQueries(queries)
+	specialist, err := h.store.GetSpecialist(ctx, specialistID)
 	if err != nil {
-		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.InvalidQueries), http.StatusBadRequest)
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get specialist %s", specialistID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialist", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	if specialist == nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Specialist not found", helpers.NotFound), http.StatusNotFound)
 		return
 	}
+	if specialist.UserID == nil || *specialist.UserID != userID {
+		if !h.authorizeBusiness(resp, req, specialist.BusinessAccountID) {
+			return
+		}
+	}
 
-	log.Ctx(req.Context()).Info().Msgf("areatype %s, city %s", areaType, city)
+	updated, err := h.store.Unlink(ctx, specialist.ID)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to unlink specialist")
+		return
+	}
+
+	helpers.WriteData(ctx, resp, updated, http.StatusOK)
+}
+
+// DeleteSpecialist removes the profile and its service assignments. Existing bookings keep their specialist id.
+func (h *Handler) DeleteSpecialist(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	specialist, ok := h.authorizeSpecialist(resp, req)
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteSpecialist(ctx, specialist.ID); err != nil {
+		writeStoreError(resp, req, err, "Failed to delete specialist")
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// SetSpecialistServices replaces the services the specialist performs. They must be services of the
+// specialist's business account.
+func (h *Handler) SetSpecialistServices(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	specialist, ok := h.authorizeSpecialist(resp, req)
+	if !ok {
+		return
+	}
+
+	var setReq SetServicesRequest
+	if err := json.NewDecoder(req.Body).Decode(&setReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+
+	errs, err := h.checkServices(ctx, specialist.BusinessAccountID, setReq.ServiceIDs)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to validate services of specialist %s", specialist.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate services", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	if len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	if err := h.store.SetServices(ctx, specialist.ID, setReq.ServiceIDs); err != nil {
+		writeStoreError(resp, req, err, "Failed to assign services")
+		return
+	}
+
+	specialist.ServiceIDs, err = h.store.ListServiceIDs(ctx, specialist.ID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to list services of specialist %s", specialist.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialist services", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteData(ctx, resp, specialist, http.StatusOK)
+}
+
+// checkServices makes sure every service exists and is offered by the business account.
+func (h *Handler) checkServices(ctx context.Context, businessAccountID string, serviceIDs []string) (helpers.FieldErrors, error) {
+	var errs helpers.FieldErrors
+	for _, serviceID := range serviceIDs {
+		service, err := h.servicesStore.GetService(ctx, serviceID)
+		if err != nil {
+			return nil, err
+		}
+		if service == nil || service.BusinessAccountID != businessAccountID {
+			errs.Add("service_ids", fmt.Sprintf("service %s is not offered by this business", serviceID))
+		}
+	}
+	return errs, nil
+}
+
+// checkArea makes sure the area type of a profile is an area of the taxonomy. Error responses are written here.
+func (h *Handler) checkArea(resp http.ResponseWriter, req *http.Request, slug *string) bool {
+	ctx := req.Context()
+	if slug == nil {
+		return true
+	}
+
+	area, err := h.taxonomyStore.GetCategory(ctx, *slug)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get category %s", *slug)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate area", helpers.InternalError), http.StatusInternalServerError)
+		return false
+	}
+	if area == nil || area.Level != taxonomy.LevelArea {
+		var errs helpers.FieldErrors
+		errs.Add("area_type", "must be the slug of an area")
+		helpers.WriteFieldErrors(resp, errs)
+		return false
+	}
+
+	return true
+}
+
+// authorizeSpecialist loads the specialist from the path and makes sure the caller owns its business account.
+// Error responses are written here.
+func (h *Handler) authorizeSpecialist(resp http.ResponseWriter, req *http.Request) (*specialists.Specialist, bool) {
+	ctx := req.Context()
+	specialistID := mux.Vars(req)["id"]
+
+	specialist, err := h.store.GetSpecialist(ctx, specialistID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get specialist %s", specialistID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialist", helpers.InternalError), http.StatusInternalServerError)
+		return nil, false
+	}
+	if specialist == nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Specialist not found", helpers.NotFound), http.StatusNotFound)
+		return nil, false
+	}
+
+	if !h.authorizeBusiness(resp, req, specialist.BusinessAccountID) {
+		return nil, false
+	}
+
+	return specialist, true
+}
+
+// authorizeBusiness makes sure the caller owns the business account. Error responses are written here.
+func (h *Handler) authorizeBusiness(resp http.ResponseWriter, req *http.Request, businessAccountID string) bool {
+	ctx := req.Context()
+
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return false
+	}
+
+	owns, err := h.businessAccountsStore.UserOwnsBusinessAccount(ctx, businessAccountID, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to validate ownership of business account %s", businessAccountID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to validate ownership", helpers.InternalError), http.StatusInternalServerError)
+		return false
+	}
+	if !owns {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Forbidden: you do not own this business account", helpers.Forbidden), http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// writeStoreError maps the errors of the specialists store to responses.
+func writeStoreError(resp http.ResponseWriter, req *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, specialists.ErrSpecialistNotFound):
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Specialist not found", helpers.NotFound), http.StatusNotFound)
+	case errors.Is(err, specialists.ErrUserAlreadyLinked):
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.Conflict), http.StatusConflict)
+	case errors.Is(err, specialists.ErrUserNotFound):
+		var errs helpers.FieldErrors
+		errs.Add("user_id", "user account not found")
+		helpers.WriteFieldErrors(resp, errs)
+	default:
+		log.Ctx(req.Context()).Error().Err(err).Msg(msg)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(msg, helpers.InternalError), http.StatusInternalServerError)
+	}
 }
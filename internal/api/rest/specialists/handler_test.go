@@ -0,0 +1,154 @@
+package specialists
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/business_accounts"
+	"booking-service/internal/store/specialists"
+
+	"github.com/gorilla/mux"
+)
+
+// fakeSpecialists keeps profiles in memory, linked the way the store links them.
+type fakeSpecialists struct {
+	specialists.Store
+	profiles map[string]*specialists.Specialist
+}
+
+func (f *fakeSpecialists) GetSpecialist(_ context.Context, id string) (*specialists.Specialist, error) {
+	return f.profiles[id], nil
+}
+
+func (f *fakeSpecialists) AcceptLink(_ context.Context, id, userID string) (*specialists.Specialist, error) {
+	profile := f.profiles[id]
+	if profile == nil || profile.UserID == nil || *profile.UserID != userID {
+		return nil, specialists.ErrSpecialistNotFound
+	}
+	if profile.LinkedAt == nil {
+		now := time.Now()
+		profile.LinkedAt = &now
+	}
+	return profile, nil
+}
+
+func (f *fakeSpecialists) Unlink(_ context.Context, id string) (*specialists.Specialist, error) {
+	profile := f.profiles[id]
+	profile.UserID, profile.LinkedAt = nil, nil
+	return profile, nil
+}
+
+func (f *fakeSpecialists) GetSchedule(_ context.Context, id string, _ time.Time) (*specialists.Schedule, error) {
+	return &specialists.Schedule{SpecialistID: id}, nil
+}
+
+type fakeBusinessAccounts struct {
+	business_accounts.Store
+	owner string
+}
+
+func (f *fakeBusinessAccounts) UserOwnsBusinessAccount(_ context.Context, _, userID string) (bool, error) {
+	return userID == f.owner, nil
+}
+
+// serveAs calls the handler for the specialist on behalf of the user.
+func serveAs(handler http.HandlerFunc, userID, specialistID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req = mux.SetURLVars(req.WithContext(helpers.WithUserID(req.Context(), userID)), map[string]string{"id": specialistID})
+	resp := httptest.NewRecorder()
+	handler(resp, req)
+	return resp
+}
+
+func newLinkTestHandler() (*Handler, *specialists.Specialist) {
+	invited := "staff"
+	profile := &specialists.Specialist{ID: "jane", BusinessAccountID: "salon", UserID: &invited}
+	store := &fakeSpecialists{profiles: map[string]*specialists.Specialist{profile.ID: profile}}
+	return &Handler{store: store, businessAccountsStore: &fakeBusinessAccounts{owner: "owner"}}, profile
+}
+
+func TestHandler_AcceptLink(t *testing.T) {
+	h, profile := newLinkTestHandler()
+
+	if resp := serveAs(h.GetSchedule, "staff", profile.ID); resp.Code != http.StatusForbidden {
+		t.Fatalf("schedule before accepting: status = %d, want %d", resp.Code, http.StatusForbidden)
+	}
+	if resp := serveAs(h.AcceptLink, "stranger", profile.ID); resp.Code != http.StatusNotFound {
+		t.Fatalf("accepted by another user: status = %d, want %d", resp.Code, http.StatusNotFound)
+	}
+	if resp := serveAs(h.AcceptLink, "staff", profile.ID); resp.Code != http.StatusOK || profile.LinkedAt == nil {
+		t.Fatalf("accepted by the invited user: status = %d, linked at %v", resp.Code, profile.LinkedAt)
+	}
+	if resp := serveAs(h.GetSchedule, "staff", profile.ID); resp.Code != http.StatusOK {
+		t.Errorf("schedule after accepting: status = %d, want %d", resp.Code, http.StatusOK)
+	}
+}
+
+func TestHandler_Unlink(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID string
+		want   int
+	}{
+		{name: "owner", userID: "owner", want: http.StatusOK},
+		{name: "invited user", userID: "staff", want: http.StatusOK},
+		{name: "another user", userID: "stranger", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, profile := newLinkTestHandler()
+
+			resp := serveAs(h.Unlink, tt.userID, profile.ID)
+
+			if resp.Code != tt.want {
+				t.Fatalf("status = %d, want %d", resp.Code, tt.want)
+			}
+			if unlinked := profile.UserID == nil; unlinked != (tt.want == http.StatusOK) {
+				t.Errorf("unlinked = %v, want %v", unlinked, tt.want == http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestValidateSpecialistRequests(t *testing.T) {
+	valid, invalid, photo := "6f1c2a8e-3b1d-4c7e-9a2f-5d8e7b6c4a3f", "jane", "ftp://example.com/jane.jpg"
+
+	tests := []struct {
+		name       string
+		errs       helpers.FieldErrors
+		wantFields []string
+	}{
+		{name: "create", errs: validateCreateSpecialistRequest(specialists.CreateSpecialistRequest{
+			BusinessAccountID: "salon", Name: "Jane", AreaType: "makeup", UserID: &valid,
+		})},
+		{name: "create without required fields", errs: validateCreateSpecialistRequest(specialists.CreateSpecialistRequest{}),
+			wantFields: []string{"business_account_id", "name", "area_type"}},
+		{name: "create with invalid user", errs: validateCreateSpecialistRequest(specialists.CreateSpecialistRequest{
+			BusinessAccountID: "salon", Name: "Jane", AreaType: "makeup", UserID: &invalid,
+		}), wantFields: []string{"user_id"}},
+		{name: "update with invalid user and photo", errs: validateUpdateSpecialistRequest(specialists.UpdateSpecialistRequest{
+			UserID: &invalid, PhotoURL: &photo,
+		}), wantFields: []string{"user_id", "photo_url"}},
+		{name: "update with rating out of range", errs: validateUpdateSpecialistRequest(specialists.UpdateSpecialistRequest{
+			Rating: ptr(5.5),
+		}), wantFields: []string{"rating"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.errs) != len(tt.wantFields) {
+				t.Fatalf("errors = %v, want errors on %v", tt.errs, tt.wantFields)
+			}
+			for i, field := range tt.wantFields {
+				if tt.errs[i].Field != field {
+					t.Errorf("error %d on %s, want %s", i, tt.errs[i].Field, field)
+				}
+			}
+		})
+	}
+}
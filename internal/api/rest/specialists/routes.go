@@ -20,4 +20,19 @@ func (r Router) RegisterRoutes(router *mux.Router) {
 	specialistRouter.Use(r.authMiddleware.Middleware)
 
 	specialistRouter.HandleFunc("/", r.handler.GetSpecialists).Methods(http.MethodGet)
+	specialistRouter.HandleFunc("/", r.handler.CreateSpecialist).Methods(http.MethodPost)
+	specialistRouter.HandleFunc("/{id}", r.handler.GetSpecialist).Methods(http.MethodGet)
+	specialistRouter.HandleFunc("/{id}", r.handler.UpdateSpecialist).Methods(http.MethodPut)
+	specialistRouter.HandleFunc("/{id}", r.handler.DeleteSpecialist).Methods(http.MethodDelete)
+	specialistRouter.HandleFunc("/{id}/services", r.handler.SetSpecialistServices).Methods(http.MethodPut)
+
+	// Linking the user account of the specialist: the owner invites it with user_id, the user accepts
+	specialistRouter.HandleFunc("/{id}/link", r.handler.AcceptLink).Methods(http.MethodPost)
+	specialistRouter.HandleFunc("/{id}/link", r.handler.Unlink).Methods(http.MethodDelete)
+
+	// Working time of the specialist, managed by the owner or the specialist themselves
+	specialistRouter.HandleFunc("/{id}/schedule", r.handler.GetSchedule).Methods(http.MethodGet)
+	specialistRouter.HandleFunc("/{id}/schedule", r.handler.SetSchedule).Methods(http.MethodPut)
+	specialistRouter.HandleFunc("/{id}/time-off", r.handler.CreateTimeOff).Methods(http.MethodPost)
+	specialistRouter.HandleFunc("/{id}/time-off/{time_off_id}", r.handler.DeleteTimeOff).Methods(http.MethodDelete)
 }
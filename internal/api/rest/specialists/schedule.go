@@ -0,0 +1,148 @@
+package specialists
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/specialists"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// GetSchedule returns the weekly shifts and breaks of the specialist with their upcoming time off.
+func (h *Handler) GetSchedule(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	specialist, ok := h.authorizeStaffMember(resp, req)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.store.GetSchedule(ctx, specialist.ID, time.Now())
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get schedule of specialist %s", specialist.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get schedule", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteData(ctx, resp, schedule, http.StatusOK)
+}
+
+// SetSchedule replaces the weekly shifts and breaks of the specialist. They narrow the working hours of the
+// business, which still apply.
+func (h *Handler) SetSchedule(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	specialist, ok := h.authorizeStaffMember(resp, req)
+	if !ok {
+		return
+	}
+
+	var setReq specialists.SetScheduleRequest
+	if err := json.NewDecoder(req.Body).Decode(&setReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+
+	if errs := validateSetScheduleRequest(setReq); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	if err := h.store.SetSchedule(ctx, specialist.ID, setReq); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to set schedule of specialist %s", specialist.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to set schedule", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	h.GetSchedule(resp, req)
+}
+
+// CreateTimeOff marks the specialist as absent, e.g. for a vacation. Existing bookings are kept.
+func (h *Handler) CreateTimeOff(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	specialist, ok := h.authorizeStaffMember(resp, req)
+	if !ok {
+		return
+	}
+
+	var createReq specialists.CreateTimeOffRequest
+	if err := json.NewDecoder(req.Body).Decode(&createReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+
+	if errs := validateCreateTimeOffRequest(createReq); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	timeOff, err := h.store.CreateTimeOff(ctx, specialist.ID, createReq)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to create time off of specialist %s", specialist.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to create time off", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteData(ctx, resp, timeOff, http.StatusCreated)
+}
+
+func (h *Handler) DeleteTimeOff(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	timeOffID := mux.Vars(req)["time_off_id"]
+
+	specialist, ok := h.authorizeStaffMember(resp, req)
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteTimeOff(ctx, specialist.ID, timeOffID); err != nil {
+		if errors.Is(err, specialists.ErrTimeOffNotFound) {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Time off not found", helpers.NotFound), http.StatusNotFound)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to delete time off %s", timeOffID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to delete time off", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeStaffMember loads the specialist from the path and makes sure the caller is either the specialist,
+// through the user account they linked, or the owner of the business account. Error responses are written here.
+func (h *Handler) authorizeStaffMember(resp http.ResponseWriter, req *http.Request) (*specialists.Specialist, bool) {
+	ctx := req.Context()
+	specialistID := mux.Vars(req)["id"]
+
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return nil, false
+	}
+
+	specialist, err := h.store.GetSpecialist(ctx, specialistID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get specialist %s", specialistID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialist", helpers.InternalError), http.StatusInternalServerError)
+		return nil, false
+	}
+	if specialist == nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Specialist not found", helpers.NotFound), http.StatusNotFound)
+		return nil, false
+	}
+
+	if specialist.LinkedTo(userID) {
+		return specialist, true
+	}
+	if !h.authorizeBusiness(resp, req, specialist.BusinessAccountID) {
+		return nil, false
+	}
+
+	return specialist, true
+}
@@ -0,0 +1,203 @@
+package specialists
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/schedules"
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/specialists"
+	"booking-service/internal/store/taxonomy"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxRankedCandidates caps the specialists ranked by next available slot, the most relevant ones.
+	maxRankedCandidates = 100
+	// availabilityHorizonDays is how far ahead the next available slot is looked for.
+	availabilityHorizonDays = 14
+)
+
+// GetSpecialists searches specialists by area type, city, business account, service category and price range.
+// Results are paginated and sorted by relevance or by the next available slot.
+func (h *Handler) GetSpecialists(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	query, err := validateGetSpecialistsQueries(req.URL.Query())
+	if err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.InvalidQueries), http.StatusBadRequest)
+		return
+	}
+
+	if query.AreaType != nil || query.Category != "" {
+		categories, err := h.taxonomyStore.ListCategories(ctx)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to list categories")
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get categories", helpers.InternalError), http.StatusInternalServerError)
+			return
+		}
+		if err := resolveTaxonomy(categories, query); err != nil {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.InvalidQueries), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if query.Sort == SortRelevance {
+		result, err := h.store.SearchSpecialists(ctx, query.SearchQuery)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to search specialists")
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialists", helpers.InternalError), http.StatusInternalServerError)
+			return
+		}
+		helpers.WriteData(ctx, resp, result, http.StatusOK)
+		return
+	}
+
+	// Availability is not known to the database, so the most relevant candidates are ranked here and paginated after
+	candidates := query.SearchQuery
+	candidates.Limit, candidates.Offset = maxRankedCandidates, 0
+	result, err := h.store.SearchSpecialists(ctx, candidates)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to search specialists")
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get specialists", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.findNextAvailable(ctx, result.Specialists, time.Now()); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to find available slots of specialists")
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get availability", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	sortByNextAvailable(result.Specialists)
+
+	result.Ranked = len(result.Specialists)
+	result.Specialists = page(result.Specialists, query.Offset, query.Limit)
+
+	helpers.WriteData(ctx, resp, result, http.StatusOK)
+}
+
+// page returns the hits of the page starting at offset.
+func page(hits []*specialists.SearchHit, offset, limit int) []*specialists.SearchHit {
+	start := min(offset, len(hits))
+	end := min(start+limit, len(hits))
+	return hits[start:end]
+}
+
+// resolveTaxonomy checks the area of the query and expands its category to the category with all of its
+// sub-categories.
+func resolveTaxonomy(categories []*taxonomy.Category, query *searchQuery) error {
+	bySlug := make(map[string]*taxonomy.Category, len(categories))
+	for _, category := range categories {
+		bySlug[category.Slug] = category
+	}
+
+	if query.AreaType != nil {
+		if area, ok := bySlug[*query.AreaType]; !ok || area.Level != taxonomy.LevelArea {
+			return errors.New("invalid specialist's area")
+		}
+	}
+	if query.Category != "" {
+		if category, ok := bySlug[query.Category]; !ok || category.Level == taxonomy.LevelArea {
+			return errors.New("category must be the slug of a category or sub-category")
+		}
+		query.Categories = taxonomy.Subtree(categories, query.Category)
+	}
+
+	return nil
+}
+
+// findNextAvailable sets the first free slot of every specialist within the availability horizon and their
+// working time, for the shortest of their matching services with its buffers and lead time. Specialists without
+// matching services have none. Schedules and bookings of all specialists are loaded at once; working hours
+// once per business.
+func (h *Handler) findNextAvailable(ctx context.Context, hits []*specialists.SearchHit, now time.Time) error {
+	var ids []string
+	for _, hit := range hits {
+		if hit.ShortestService.DurationMinutes > 0 {
+			ids = append(ids, hit.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// The day before covers the buffers of bookings ending just before now in any timezone
+	from, to := now.AddDate(0, 0, -1), now.AddDate(0, 0, availabilityHorizonDays+1)
+	staffSchedules, err := h.store.ListSchedules(ctx, ids, from)
+	if err != nil {
+		return err
+	}
+	busyWindows, err := h.bookingsStore.ListSpecialistBusyWindows(ctx, ids, from, to)
+	if err != nil {
+		return err
+	}
+
+	calendars := make(map[string]*schedules.Calendar)
+	for _, hit := range hits {
+		if hit.ShortestService.DurationMinutes == 0 {
+			continue
+		}
+
+		calendar, ok := calendars[hit.BusinessAccountID]
+		if !ok {
+			hours, err := h.businessAccountsStore.GetWorkingHours(ctx, hit.BusinessAccountID)
+			if err != nil {
+				return err
+			}
+			if calendar, err = schedules.NewCalendar(hours); err != nil {
+				return err
+			}
+			calendars[hit.BusinessAccountID] = calendar
+		}
+
+		staff, err := schedules.NewStaffHours(staffSchedules[hit.ID])
+		if err != nil {
+			return err
+		}
+
+		start := calendar.Date(now)
+		open := calendar.WithStaff(staff).Expand(start, start.AddDate(0, 0, availabilityHorizonDays))
+		hit.NextAvailable = nextAvailable(open, busyWindows[hit.ID], hit.ShortestService, h.slotStep, calendar.Location, now)
+	}
+	return nil
+}
+
+// nextAvailable returns the start of the first slot of the service in the open intervals, clear of the busy
+// windows with its buffers and at least its lead time from now, or nil if there is none.
+func nextAvailable(open []schedules.Interval, windows []bookings.TimeWindow, service specialists.ShortestService,
+	step time.Duration, loc *time.Location, now time.Time) *time.Time {
+	busy := make([]schedules.Interval, 0, len(windows))
+	for _, w := range windows {
+		busy = append(busy, schedules.Interval{Start: w.StartTime, End: w.EndTime})
+	}
+
+	slots := schedules.Slots(open, busy, schedules.SlotRules{
+		Duration:     time.Duration(service.DurationMinutes) * time.Minute,
+		Step:         step,
+		Location:     loc,
+		BufferBefore: time.Duration(service.BufferBeforeMinutes) * time.Minute,
+		BufferAfter:  time.Duration(service.BufferAfterMinutes) * time.Minute,
+		NotBefore:    now.Add(time.Duration(service.MinLeadMinutes) * time.Minute),
+	})
+	if len(slots) == 0 {
+		return nil
+	}
+	return &slots[0].StartTime
+}
+
+// sortByNextAvailable puts the earliest available specialists first and those without a free slot last,
+// keeping the relevance order among equals.
+func sortByNextAvailable(hits []*specialists.SearchHit) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		a, b := hits[i].NextAvailable, hits[j].NextAvailable
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.Before(*b)
+	})
+}
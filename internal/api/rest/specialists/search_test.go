@@ -0,0 +1,104 @@
+package specialists
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"booking-service/internal/schedules"
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/specialists"
+)
+
+func TestValidateGetSpecialistsQueries(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+		check   func(*searchQuery) bool
+	}{
+		{name: "defaults", query: "", check: func(q *searchQuery) bool {
+			return q.Limit == defaultLimit && q.Offset == 0 && q.Sort == SortRelevance
+		}},
+		{name: "filters", query: "City=Riga&type=salon&category=makeup&sort=next_available&limit=5&offset=10", check: func(q *searchQuery) bool {
+			return *q.City == "Riga" && *q.AreaType == "salon" && q.Category == "makeup" &&
+				q.Sort == SortNextAvailable && q.Limit == 5 && q.Offset == 10
+		}},
+		{name: "price range", query: "min_price=1000&max_price=5000&currency=EUR", check: func(q *searchQuery) bool {
+			return *q.MinPrice == 1000 && *q.MaxPrice == 5000 && *q.Currency == "EUR"
+		}},
+		{name: "price range without currency", query: "min_price=1000", wantErr: true},
+		{name: "inverted price range", query: "min_price=5000&max_price=1000&currency=EUR", wantErr: true},
+		{name: "unknown sort", query: "sort=rating", wantErr: true},
+		{name: "limit too large", query: "limit=1000", wantErr: true},
+		{name: "negative offset", query: "offset=-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query, err := validateGetSpecialistsQueries(queries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateGetSpecialistsQueries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !tt.check(query) {
+				t.Errorf("validateGetSpecialistsQueries() = %+v", query)
+			}
+		})
+	}
+}
+
+func TestNextAvailable(t *testing.T) {
+	now := time.Date(2030, time.March, 4, 8, 50, 0, 0, time.UTC)
+	open := []schedules.Interval{{Start: now.Add(-50 * time.Minute), End: now.Add(8*time.Hour + 10*time.Minute)}}
+	busy := []bookings.TimeWindow{{StartTime: now.Add(70 * time.Minute), EndTime: now.Add(130 * time.Minute)}}
+	at := func(hour, minute int) time.Time { return time.Date(2030, time.March, 4, hour, minute, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name    string
+		service specialists.ShortestService
+		open    []schedules.Interval
+		want    *time.Time
+	}{
+		{name: "first slot after now", service: specialists.ShortestService{DurationMinutes: 60}, open: open, want: ptr(at(9, 0))},
+		{name: "buffer after", service: specialists.ShortestService{DurationMinutes: 60, BufferAfterMinutes: 15}, open: open, want: ptr(at(11, 0))},
+		{name: "buffer before", service: specialists.ShortestService{DurationMinutes: 90, BufferBeforeMinutes: 15}, open: open, want: ptr(at(11, 15))},
+		{name: "lead time", service: specialists.ShortestService{DurationMinutes: 30, MinLeadMinutes: 60}, open: open, want: ptr(at(11, 0))},
+		{name: "no room", service: specialists.ShortestService{DurationMinutes: 60}, open: nil, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextAvailable(tt.open, busy, tt.service, 15*time.Minute, time.UTC, now)
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("nextAvailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortByNextAvailable(t *testing.T) {
+	now := time.Now()
+	hits := []*specialists.SearchHit{
+		{Specialist: &specialists.Specialist{ID: "none"}},
+		{Specialist: &specialists.Specialist{ID: "later"}, NextAvailable: ptr(now.Add(2 * time.Hour))},
+		{Specialist: &specialists.Specialist{ID: "none-too"}},
+		{Specialist: &specialists.Specialist{ID: "sooner"}, NextAvailable: ptr(now.Add(time.Hour))},
+	}
+
+	sortByNextAvailable(hits)
+
+	want := []string{"sooner", "later", "none", "none-too"}
+	for i, hit := range hits {
+		if hit.ID != want[i] {
+			t.Fatalf("sortByNextAvailable() order at %d = %s, want %s", i, hit.ID, want[i])
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -1,24 +1,27 @@
 package specialists
 
 const (
-	Type = "type"
-	City = "City"
+	Type              = "type"
+	City              = "City"
+	BusinessAccountID = "business_account_id"
+	Category          = "category"
+	Currency          = "currency"
+	MinPrice          = "min_price"
+	MaxPrice          = "max_price"
+	Sort              = "sort"
+	Limit             = "limit"
+	Offset            = "offset"
+
+	defaultLimit = 20
+	maxLimit     = 100
 )
 
-type AreaType string
+// SortOrder orders search results.
+type SortOrder string
 
 const (
-	Makeup AreaType = "makeup"
-	Sport  AreaType = "sport"
+	// SortRelevance puts the specialists performing the most matching services first.
+	SortRelevance SortOrder = "relevance"
+	// SortNextAvailable puts the specialists with the earliest free slot first.
+	SortNextAvailable SortOrder = "next_available"
 )
-
-func StringToAreaType(s string) (AreaType, bool) {
-	switch {
-	case s == string(Makeup):
-		return Makeup, true
-	case s == string(Sport):
-		return Sport, true
-	default:
-		return "", false
-	}
-}
@@ -2,17 +2,198 @@ package specialists
 
 import (
 	"errors"
+	"fmt"
 	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/schedules"
+	"booking-service/internal/store/business_accounts"
+	"booking-service/internal/store/specialists"
+	"booking-service/pkg/money"
+
+	"github.com/google/uuid"
 )
 
-func validateGetSpecialistsQueries(queries url.Values) (AreaType, string, error) {
-	areaType := queries.Get(Type)
-	city := queries.Get(City)
+// maxReasonLength is the length of the reason column of time off.
+const maxReasonLength = 255
+
+// searchQuery is a parsed GET /specialists query. The category is resolved against the taxonomy by the handler.
+type searchQuery struct {
+	specialists.SearchQuery
+	Category string
+	Sort     SortOrder
+}
+
+func validateGetSpecialistsQueries(queries url.Values) (*searchQuery, error) {
+	query := &searchQuery{
+		SearchQuery: specialists.SearchQuery{Limit: defaultLimit},
+		Sort:        SortRelevance,
+	}
+
+	optional := map[string]**string{
+		City:              &query.City,
+		BusinessAccountID: &query.BusinessAccountID,
+		Type:              &query.AreaType,
+	}
+	for param, field := range optional {
+		if value := queries.Get(param); value != "" {
+			*field = &value
+		}
+	}
+
+	query.Category = queries.Get(Category)
+
+	var err error
+	if query.MinPrice, err = parsePrice(queries, MinPrice); err != nil {
+		return nil, err
+	}
+	if query.MaxPrice, err = parsePrice(queries, MaxPrice); err != nil {
+		return nil, err
+	}
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return nil, errors.New("min_price cannot exceed max_price")
+	}
+	if code := queries.Get(Currency); code != "" {
+		currency, err := money.ParseCurrency(code)
+		if err != nil {
+			return nil, err
+		}
+		query.Currency = (*string)(&currency)
+	} else if query.MinPrice != nil || query.MaxPrice != nil {
+		return nil, errors.New("currency is required with a price range")
+	}
+
+	switch sort := SortOrder(queries.Get(Sort)); sort {
+	case "":
+	case SortRelevance, SortNextAvailable:
+		query.Sort = sort
+	default:
+		return nil, errors.New("sort must be relevance or next_available")
+	}
+
+	if limitStr := queries.Get(Limit); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxLimit)
+		}
+		query.Limit = limit
+	}
+	if offsetStr := queries.Get(Offset); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return nil, errors.New("offset cannot be negative")
+		}
+		query.Offset = offset
+	}
+
+	return query, nil
+}
+
+// parsePrice reads an optional price bound in minor units.
+func parsePrice(queries url.Values, param string) (*int64, error) {
+	value := queries.Get(param)
+	if value == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || price < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative amount in minor units", param)
+	}
+	return &price, nil
+}
+
+func validateCreateSpecialistRequest(req specialists.CreateSpecialistRequest) helpers.FieldErrors {
+	var errs helpers.FieldErrors
+	if req.BusinessAccountID == "" {
+		errs.Add("business_account_id", "is required")
+	}
+	if req.Name == "" {
+		errs.Add("name", "is required")
+	}
+	if req.AreaType == "" {
+		errs.Add("area_type", "is required")
+	}
+	validateProfile(&errs, req.UserID, req.PhotoURL, req.Rating)
+	return errs
+}
+
+func validateUpdateSpecialistRequest(req specialists.UpdateSpecialistRequest) helpers.FieldErrors {
+	var errs helpers.FieldErrors
+	if req.Name != nil && *req.Name == "" {
+		errs.Add("name", "cannot be empty")
+	}
+	if req.AreaType != nil && *req.AreaType == "" {
+		errs.Add("area_type", "cannot be empty")
+	}
+	validateProfile(&errs, req.UserID, req.PhotoURL, req.Rating)
+	return errs
+}
+
+// validateProfile checks the optional fields shared by create and update requests.
+func validateProfile(errs *helpers.FieldErrors, userID, photoURL *string, rating *float64) {
+	if userID != nil {
+		if _, err := uuid.Parse(*userID); err != nil {
+			errs.Add("user_id", "must be the id of a user account")
+		}
+	}
+	if photoURL != nil {
+		u, err := url.Parse(*photoURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.Add("photo_url", "must be an absolute http(s) URL")
+		}
+	}
+	if rating != nil && (*rating < 0 || *rating > 5) {
+		errs.Add("rating", "must be between 0 and 5")
+	}
+}
+
+func validateSetScheduleRequest(req specialists.SetScheduleRequest) helpers.FieldErrors {
+	var errs helpers.FieldErrors
+	validateWeekly(&errs, "weekly", req.Weekly)
+	validateWeekly(&errs, "breaks", req.Breaks)
+	return errs
+}
 
-	area, ok := StringToAreaType(areaType)
-	if !ok {
-		return "", "", errors.New("invalid specialist's area")
+// validateWeekly checks that every interval is well-formed and that intervals of one day do not overlap.
+func validateWeekly(errs *helpers.FieldErrors, field string, intervals []business_accounts.WorkingInterval) {
+	byDay := make(map[time.Weekday][]schedules.TimeRange)
+	for _, interval := range intervals {
+		if interval.Weekday < time.Sunday || interval.Weekday > time.Saturday {
+			errs.Add(field, fmt.Sprintf("weekday must be between 0 (Sunday) and 6 (Saturday), got %d", interval.Weekday))
+			continue
+		}
+		r, err := schedules.ParseTimeInterval(interval.TimeInterval)
+		if err != nil {
+			errs.Add(field, err.Error())
+			continue
+		}
+		byDay[interval.Weekday] = append(byDay[interval.Weekday], r)
 	}
 
-	return area, city, nil
+	for day, ranges := range byDay {
+		sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+		for i := 1; i < len(ranges); i++ {
+			if ranges[i].Start < ranges[i-1].End {
+				errs.Add(field, fmt.Sprintf("%s: intervals must not overlap", day))
+				break
+			}
+		}
+	}
+}
+
+func validateCreateTimeOffRequest(req specialists.CreateTimeOffRequest) helpers.FieldErrors {
+	var errs helpers.FieldErrors
+	if req.StartTime.IsZero() {
+		errs.Add("start_time", "is required")
+	}
+	if !req.EndTime.After(req.StartTime) {
+		errs.Add("end_time", "must be after start_time")
+	}
+	if req.Reason != nil && len(*req.Reason) > maxReasonLength {
+		errs.Add("reason", fmt.Sprintf("must be at most %d characters", maxReasonLength))
+	}
+	return errs
 }
@@ -0,0 +1,212 @@
+package taxonomy
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/taxonomy"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// Locale selects the language of the display names.
+const Locale = "locale"
+
+type Handler struct {
+	store taxonomy.Store
+	// admins are the ids of the users allowed to change the taxonomy.
+	admins map[string]bool
+}
+
+func NewHandler(store taxonomy.Store, adminUserIDs []string) *Handler {
+	admins := make(map[string]bool, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		admins[id] = true
+	}
+
+	return &Handler{
+		store:  store,
+		admins: admins,
+	}
+}
+
+type ListCategoriesResponse struct {
+	Categories []*taxonomy.Category `json:"categories"`
+}
+
+// ListCategories returns the whole taxonomy as a tree of areas, with names in the requested locale.
+func (h *Handler) ListCategories(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	categories, err := h.store.ListCategories(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to list categories")
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get categories", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	tree := taxonomy.Tree(categories)
+	localize(tree, locale(req))
+	if tree == nil {
+		tree = []*taxonomy.Category{}
+	}
+
+	helpers.WriteData(ctx, resp, ListCategoriesResponse{Categories: tree}, http.StatusOK)
+}
+
+// GetCategory returns a category with the categories below it.
+func (h *Handler) GetCategory(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	slug := mux.Vars(req)["slug"]
+
+	categories, err := h.store.ListCategories(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to list categories")
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get category", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	category := find(taxonomy.Tree(categories), slug)
+	if category == nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Category not found", helpers.NotFound), http.StatusNotFound)
+		return
+	}
+	localize([]*taxonomy.Category{category}, locale(req))
+
+	helpers.WriteData(ctx, resp, category, http.StatusOK)
+}
+
+// CreateCategory adds an area, or a category or sub-category under parent_slug.
+func (h *Handler) CreateCategory(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if !h.authorizeAdmin(resp, req) {
+		return
+	}
+
+	var createReq taxonomy.CreateCategoryRequest
+	if err := json.NewDecoder(req.Body).Decode(&createReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+
+	if errs := validateCreateCategoryRequest(createReq); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	category, err := h.store.CreateCategory(ctx, createReq)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to create category")
+		return
+	}
+
+	helpers.WriteData(ctx, resp, category, http.StatusCreated)
+}
+
+// UpdateCategory changes the slug or the display names of a category. Names given replace all of them.
+func (h *Handler) UpdateCategory(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if !h.authorizeAdmin(resp, req) {
+		return
+	}
+
+	var updateReq taxonomy.UpdateCategoryRequest
+	if err := json.NewDecoder(req.Body).Decode(&updateReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+
+	if errs := validateUpdateCategoryRequest(updateReq); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	category, err := h.store.UpdateCategory(ctx, mux.Vars(req)["slug"], updateReq)
+	if err != nil {
+		writeStoreError(resp, req, err, "Failed to update category")
+		return
+	}
+
+	helpers.WriteData(ctx, resp, category, http.StatusOK)
+}
+
+// DeleteCategory removes a category nothing refers to anymore.
+func (h *Handler) DeleteCategory(resp http.ResponseWriter, req *http.Request) {
+	if !h.authorizeAdmin(resp, req) {
+		return
+	}
+
+	if err := h.store.DeleteCategory(req.Context(), mux.Vars(req)["slug"]); err != nil {
+		writeStoreError(resp, req, err, "Failed to delete category")
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeAdmin makes sure the caller may change the taxonomy. Error responses are written here.
+func (h *Handler) authorizeAdmin(resp http.ResponseWriter, req *http.Request) bool {
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return false
+	}
+
+	if !h.admins[userID] {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Forbidden: only admins can change categories", helpers.Forbidden), http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// locale returns the requested locale of display names.
+func locale(req *http.Request) string {
+	if locale := req.URL.Query().Get(Locale); locale != "" {
+		return locale
+	}
+	return taxonomy.DefaultLocale
+}
+
+// localize sets the display names of the categories and their children.
+func localize(categories []*taxonomy.Category, locale string) {
+	for _, category := range categories {
+		category.Name = category.DisplayName(locale)
+		localize(category.Children, locale)
+	}
+}
+
+// find returns the category with the slug from the tree.
+func find(categories []*taxonomy.Category, slug string) *taxonomy.Category {
+	for _, category := range categories {
+		if category.Slug == slug {
+			return category
+		}
+		if found := find(category.Children, slug); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// writeStoreError maps the errors of the taxonomy store to responses.
+func writeStoreError(resp http.ResponseWriter, req *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, taxonomy.ErrCategoryNotFound):
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Category not found", helpers.NotFound), http.StatusNotFound)
+	case errors.Is(err, taxonomy.ErrParentNotFound), errors.Is(err, taxonomy.ErrTooDeep):
+		var errs helpers.FieldErrors
+		errs.Add("parent_slug", err.Error())
+		helpers.WriteFieldErrors(resp, errs)
+	case errors.Is(err, taxonomy.ErrSlugTaken), errors.Is(err, taxonomy.ErrCategoryInUse):
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(err.Error(), helpers.Conflict), http.StatusConflict)
+	default:
+		log.Ctx(req.Context()).Error().Err(err).Msg(msg)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse(msg, helpers.InternalError), http.StatusInternalServerError)
+	}
+}
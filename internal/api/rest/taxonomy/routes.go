@@ -0,0 +1,27 @@
+package taxonomy
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type Router struct {
+	handler        *Handler
+	authMiddleware mux.MiddlewareFunc
+}
+
+func NewRouter(handler *Handler, authMiddleware mux.MiddlewareFunc) Router {
+	return Router{handler: handler, authMiddleware: authMiddleware}
+}
+
+func (r Router) RegisterRoutes(router *mux.Router) {
+	categoriesRouter := router.PathPrefix("/categories").Subrouter()
+	categoriesRouter.Use(r.authMiddleware.Middleware)
+
+	categoriesRouter.HandleFunc("", r.handler.ListCategories).Methods(http.MethodGet)
+	categoriesRouter.HandleFunc("", r.handler.CreateCategory).Methods(http.MethodPost)
+	categoriesRouter.HandleFunc("/{slug}", r.handler.GetCategory).Methods(http.MethodGet)
+	categoriesRouter.HandleFunc("/{slug}", r.handler.UpdateCategory).Methods(http.MethodPut)
+	categoriesRouter.HandleFunc("/{slug}", r.handler.DeleteCategory).Methods(http.MethodDelete)
+}
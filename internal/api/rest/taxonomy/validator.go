@@ -0,0 +1,67 @@
+package taxonomy
+
+import (
+	"fmt"
+	"regexp"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/taxonomy"
+)
+
+const (
+	maxSlugLength = 64
+	maxNameLength = 100
+)
+
+var (
+	slugPattern   = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+)
+
+func validateCreateCategoryRequest(req taxonomy.CreateCategoryRequest) helpers.FieldErrors {
+	var errs helpers.FieldErrors
+	validateSlug(&errs, "slug", req.Slug)
+	if req.ParentSlug != nil && *req.ParentSlug == "" {
+		errs.Add("parent_slug", "cannot be empty")
+	}
+	if len(req.Names) == 0 {
+		errs.Add("names", "is required")
+	}
+	validateNames(&errs, req.Names)
+	return errs
+}
+
+func validateUpdateCategoryRequest(req taxonomy.UpdateCategoryRequest) helpers.FieldErrors {
+	var errs helpers.FieldErrors
+	if req.Slug != nil {
+		validateSlug(&errs, "slug", *req.Slug)
+	}
+	if req.Names != nil {
+		validateNames(&errs, req.Names)
+	}
+	return errs
+}
+
+func validateSlug(errs *helpers.FieldErrors, field, slug string) {
+	if len(slug) > maxSlugLength || !slugPattern.MatchString(slug) {
+		errs.Add(field, fmt.Sprintf("must be lowercase letters and digits separated by dashes, up to %d characters", maxSlugLength))
+	}
+}
+
+// validateNames checks localized display names. A name in the default locale is required as the fallback.
+func validateNames(errs *helpers.FieldErrors, names map[string]string) {
+	if len(names) == 0 {
+		return
+	}
+	if names[taxonomy.DefaultLocale] == "" {
+		errs.Add("names", fmt.Sprintf("a name in %q is required", taxonomy.DefaultLocale))
+	}
+	for locale, name := range names {
+		if !localePattern.MatchString(locale) {
+			errs.Add("names", fmt.Sprintf("invalid locale %q", locale))
+		}
+		if name == "" || len(name) > maxNameLength {
+			errs.Add("names", fmt.Sprintf("name in %q must be 1 to %d characters", locale, maxNameLength))
+		}
+	}
+}
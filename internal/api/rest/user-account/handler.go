@@ -24,10 +24,9 @@ func NewHandler(usersStore users.Store) *Handler {
 }
 
 func (h *Handler) GetUserAccount(w http.ResponseWriter, r *http.Request) {
-	userID, err := helpers.GetUserIDFromJWT(r.Header.Get("Authorization"))
-
-	if err != nil {
-		helpers.WriteErrorResponse(w, helpers.NewErrorResponse(err.Error(), helpers.InvalidTokenErr), http.StatusUnauthorized)
+	userID, ok := helpers.UserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteErrorResponse(w, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
 		return
 	}
 
@@ -55,9 +54,9 @@ func (h *Handler) UpdateUserAccount(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "User ID is required", http.StatusBadRequest)
 		return
 	}
-	userIDToken, err := helpers.GetUserIDFromJWT(r.Header.Get("Authorization"))
-	if err != nil {
-		helpers.WriteErrorResponse(w, helpers.NewErrorResponse(err.Error(), helpers.InvalidTokenErr), http.StatusUnauthorized)
+	userIDToken, ok := helpers.UserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteErrorResponse(w, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
 		return
 	}
 
@@ -122,9 +121,9 @@ func (h *Handler) DeleteUserAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userIDToken, err := helpers.GetUserIDFromJWT(r.Header.Get("Authorization"))
-	if err != nil {
-		helpers.WriteErrorResponse(w, helpers.NewErrorResponse(err.Error(), helpers.InvalidTokenErr), http.StatusUnauthorized)
+	userIDToken, ok := helpers.UserIDFromContext(r.Context())
+	if !ok {
+		helpers.WriteErrorResponse(w, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
 		return
 	}
 
@@ -135,7 +134,7 @@ func (h *Handler) DeleteUserAccount(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	err = h.usersStore.DeleteUser(ctx, userID)
+	err := h.usersStore.DeleteUser(ctx, userID)
 	if err != nil {
 		if errors.Is(err, users.ErrUserNotFound) {
 			log.Ctx(ctx).Error().Err(err).Msgf("user %s not found", userID)
@@ -0,0 +1,215 @@
+package waitlist
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/services"
+	"booking-service/internal/store/waitlist"
+	waitlistOffers "booking-service/internal/waitlist"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+type Handler struct {
+	store         waitlist.Store
+	bookingsStore bookings.Store
+	servicesStore services.Store
+	offerer       *waitlistOffers.Offerer
+}
+
+func NewHandler(store waitlist.Store, bookingsStore bookings.Store, servicesStore services.Store, offerer *waitlistOffers.Offerer) *Handler {
+	return &Handler{
+		store:         store,
+		bookingsStore: bookingsStore,
+		servicesStore: servicesStore,
+		offerer:       offerer,
+	}
+}
+
+type ListMyWaitlistResponse struct {
+	Entries []*waitlist.Entry `json:"entries"`
+}
+
+// JoinWaitlist registers the calling user's interest in a slot of a service within a time window.
+func (h *Handler) JoinWaitlist(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return
+	}
+
+	var createReq waitlist.CreateEntryRequest
+	if err := json.NewDecoder(req.Body).Decode(&createReq); err != nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Invalid request body", helpers.InvalidRequest), http.StatusBadRequest)
+		return
+	}
+
+	if errs := validateCreateEntryRequest(createReq); len(errs) > 0 {
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	service, err := h.servicesStore.GetService(ctx, createReq.ServiceID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get service %s", createReq.ServiceID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get service", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	if service == nil || !service.IsActive || service.BusinessAccountID != createReq.BusinessID {
+		var errs helpers.FieldErrors
+		errs.Add("service_id", "service is not offered by this business")
+		helpers.WriteFieldErrors(resp, errs)
+		return
+	}
+
+	createReq.UserID = userID
+	entry, err := h.store.CreateEntry(ctx, createReq)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to create waitlist entry")
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to join waitlist", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteData(ctx, resp, entry, http.StatusCreated)
+}
+
+// ListMyWaitlist returns the waitlist entries of the calling user, including the offers waiting to be claimed.
+func (h *Handler) ListMyWaitlist(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return
+	}
+
+	entries, err := h.store.ListUserEntries(ctx, userID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to list waitlist entries of user %s", userID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get waitlist", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteData(ctx, resp, ListMyWaitlistResponse{Entries: entries}, http.StatusOK)
+}
+
+// ClaimOffer turns the hold offered to the entry into a regular pending booking. The store fulfills the entry
+// together with the booking.
+func (h *Handler) ClaimOffer(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	entry, userID, ok := h.authorizeEntry(resp, req)
+	if !ok {
+		return
+	}
+
+	if entry.Status != waitlist.StatusOffered || entry.BookingID == nil {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("No offer to claim", helpers.Conflict), http.StatusConflict)
+		return
+	}
+
+	hold, err := h.bookingsStore.GetBooking(ctx, *entry.BookingID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get held booking %s", *entry.BookingID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get booking", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+	if hold == nil || hold.Status != bookings.StatusHeld || hold.HoldExpiresAt == nil || !hold.HoldExpiresAt.After(time.Now()) {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("The offer has expired", helpers.Conflict), http.StatusConflict)
+		return
+	}
+
+	booking, err := h.bookingsStore.TransitionBooking(ctx, hold.ID, bookings.StatusPending, userID)
+	if err != nil {
+		if errors.Is(err, bookings.ErrInvalidTransition) {
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("The offer has expired", helpers.Conflict), http.StatusConflict)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to claim held booking %s", hold.ID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to claim offer", helpers.InternalError), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.WriteData(ctx, resp, booking, http.StatusOK)
+}
+
+// LeaveWaitlist cancels the entry. A pending offer is declined and passes to the next customer.
+func (h *Handler) LeaveWaitlist(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	entry, userID, ok := h.authorizeEntry(resp, req)
+	if !ok {
+		return
+	}
+
+	switch entry.Status {
+	case waitlist.StatusWaiting:
+		_, err := h.store.UpdateStatus(ctx, entry.ID, waitlist.StatusWaiting, waitlist.StatusCancelled)
+		if err != nil {
+			if errors.Is(err, waitlist.ErrEntryNotFound) {
+				// An offer was made in the meantime
+				helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("The entry has changed, try again", helpers.Conflict), http.StatusConflict)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to cancel waitlist entry %s", entry.ID)
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to leave waitlist", helpers.InternalError), http.StatusInternalServerError)
+			return
+		}
+		resp.WriteHeader(http.StatusNoContent)
+	case waitlist.StatusOffered:
+		hold, err := h.bookingsStore.GetBooking(ctx, *entry.BookingID)
+		if err == nil && hold == nil {
+			err = bookings.ErrBookingNotFound
+		}
+		if err == nil {
+			err = h.offerer.ReleaseHold(ctx, hold, userID, waitlist.StatusCancelled)
+		}
+		if errors.Is(err, bookings.ErrBookingNotFound) || errors.Is(err, bookings.ErrInvalidTransition) {
+			// The offer was claimed or released in the meantime, which already settled the entry
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("The entry has changed, try again", helpers.Conflict), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to decline offer of waitlist entry %s", entry.ID)
+			helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to leave waitlist", helpers.InternalError), http.StatusInternalServerError)
+			return
+		}
+		resp.WriteHeader(http.StatusNoContent)
+	default:
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("The entry is not active anymore", helpers.Conflict), http.StatusConflict)
+	}
+}
+
+// authorizeEntry loads the entry from the path and makes sure it belongs to the caller.
+// Error responses are written here.
+func (h *Handler) authorizeEntry(resp http.ResponseWriter, req *http.Request) (*waitlist.Entry, string, bool) {
+	ctx := req.Context()
+	entryID := mux.Vars(req)["id"]
+
+	userID, ok := helpers.UserIDFromContext(req.Context())
+	if !ok {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Unauthorized: invalid token", helpers.InvalidTokenErr), http.StatusUnauthorized)
+		return nil, "", false
+	}
+
+	entry, err := h.store.GetEntry(ctx, entryID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get waitlist entry %s", entryID)
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Failed to get waitlist entry", helpers.InternalError), http.StatusInternalServerError)
+		return nil, "", false
+	}
+	if entry == nil || entry.UserID != userID {
+		helpers.WriteErrorResponse(resp, helpers.NewErrorResponse("Waitlist entry not found", helpers.NotFound), http.StatusNotFound)
+		return nil, "", false
+	}
+
+	return entry, userID, true
+}
@@ -0,0 +1,26 @@
+package waitlist
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type Router struct {
+	handler        *Handler
+	authMiddleware mux.MiddlewareFunc
+}
+
+func NewRouter(handler *Handler, authMiddleware mux.MiddlewareFunc) Router {
+	return Router{handler: handler, authMiddleware: authMiddleware}
+}
+
+func (r Router) RegisterRoutes(router *mux.Router) {
+	waitlistRouter := router.PathPrefix("/waitlist").Subrouter()
+	waitlistRouter.Use(r.authMiddleware.Middleware)
+
+	waitlistRouter.HandleFunc("", r.handler.JoinWaitlist).Methods(http.MethodPost)
+	waitlistRouter.HandleFunc("", r.handler.ListMyWaitlist).Methods(http.MethodGet)
+	waitlistRouter.HandleFunc("/{id}", r.handler.LeaveWaitlist).Methods(http.MethodDelete)
+	waitlistRouter.HandleFunc("/{id}/claim", r.handler.ClaimOffer).Methods(http.MethodPost)
+}
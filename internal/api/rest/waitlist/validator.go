@@ -0,0 +1,37 @@
+package waitlist
+
+import (
+	"time"
+
+	"booking-service/internal/api/rest/helpers"
+	"booking-service/internal/store/waitlist"
+)
+
+// maxWindowDays caps how long a waitlist entry can wait for a slot.
+const maxWindowDays = 31
+
+func validateCreateEntryRequest(req waitlist.CreateEntryRequest) helpers.FieldErrors {
+	var errs helpers.FieldErrors
+	if req.BusinessID == "" {
+		errs.Add("business_id", "is required")
+	}
+	if req.ServiceID == "" {
+		errs.Add("service_id", "is required")
+	}
+	if req.WindowStart.IsZero() {
+		errs.Add("window_start", "is required")
+	}
+	if req.WindowEnd.IsZero() {
+		errs.Add("window_end", "is required")
+	} else if !req.WindowEnd.After(time.Now()) {
+		errs.Add("window_end", "must be in the future")
+	}
+	if !req.WindowStart.IsZero() && !req.WindowEnd.IsZero() {
+		if !req.WindowEnd.After(req.WindowStart) {
+			errs.Add("window_end", "must be after window_start")
+		} else if req.WindowEnd.Sub(req.WindowStart) > maxWindowDays*24*time.Hour {
+			errs.Add("window_end", "the window cannot be longer than 31 days")
+		}
+	}
+	return errs
+}
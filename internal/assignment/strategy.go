@@ -0,0 +1,114 @@
+package assignment
+
+import (
+	"sort"
+	"time"
+)
+
+// Names of the strategies, as kept in the booking policy of a business and on the bookings they assigned.
+const (
+	RoundRobin   = "round_robin"
+	LeastBooked  = "least_booked"
+	HighestRated = "highest_rated"
+)
+
+// Candidate is a specialist who performs the booked services and is free for the whole appointment.
+type Candidate struct {
+	SpecialistID string
+	// BookingsThatDay counts the active bookings of the specialist on the day of the appointment.
+	BookingsThatDay int
+	// LastAssignedAt is when the specialist was last assigned a booking automatically, nil if never.
+	LastAssignedAt *time.Time
+	// Rating is the rating of the specialist, nil if they are not rated.
+	Rating *float64
+}
+
+// Strategy decides which specialist gets a booking made for any available specialist.
+type Strategy interface {
+	// Rank orders the candidates by preference. The input is left unchanged.
+	Rank(candidates []Candidate) []Candidate
+}
+
+// strategies are the available strategies by name.
+var strategies = map[string]Strategy{
+	RoundRobin:   roundRobin{},
+	LeastBooked:  leastBooked{},
+	HighestRated: highestRated{},
+}
+
+// ByName returns the strategy with the given name.
+func ByName(name string) (Strategy, bool) {
+	strategy, ok := strategies[name]
+	return strategy, ok
+}
+
+// Names returns the names of all strategies, sorted.
+func Names() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// roundRobin takes turns: the specialist assigned least recently goes first, those never assigned before all.
+type roundRobin struct{}
+
+func (roundRobin) Rank(candidates []Candidate) []Candidate {
+	ranked := append([]Candidate(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool { return assignedBefore(ranked[i], ranked[j]) })
+	return ranked
+}
+
+// leastBooked evens out the day: the specialist with the fewest bookings that day goes first, taking turns
+// among equals.
+type leastBooked struct{}
+
+func (leastBooked) Rank(candidates []Candidate) []Candidate {
+	ranked := append([]Candidate(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].BookingsThatDay != ranked[j].BookingsThatDay {
+			return ranked[i].BookingsThatDay < ranked[j].BookingsThatDay
+		}
+		return assignedBefore(ranked[i], ranked[j])
+	})
+	return ranked
+}
+
+// highestRated favours the best rated specialist, taking turns among equals. Specialists who are not rated go
+// last.
+type highestRated struct{}
+
+func (highestRated) Rank(candidates []Candidate) []Candidate {
+	ranked := append([]Candidate(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].Rating, ranked[j].Rating
+		switch {
+		case a != nil && b == nil:
+			return true
+		case a == nil && b != nil:
+			return false
+		case a != nil && *a != *b:
+			return *a > *b
+		default:
+			return assignedBefore(ranked[i], ranked[j])
+		}
+	})
+	return ranked
+}
+
+// assignedBefore reports whether a has waited longer for an assignment than b. Ties are broken by id, so the
+// order does not depend on the order of the candidates.
+func assignedBefore(a, b Candidate) bool {
+	switch {
+	case a.LastAssignedAt == nil && b.LastAssignedAt != nil:
+		return true
+	case a.LastAssignedAt != nil && b.LastAssignedAt == nil:
+		return false
+	case a.LastAssignedAt != nil && !a.LastAssignedAt.Equal(*b.LastAssignedAt):
+		return a.LastAssignedAt.Before(*b.LastAssignedAt)
+	default:
+		return a.SpecialistID < b.SpecialistID
+	}
+}
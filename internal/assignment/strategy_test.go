@@ -0,0 +1,50 @@
+package assignment
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStrategies(t *testing.T) {
+	earlier := time.Date(2030, time.March, 4, 9, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	good, best := 4.5, 4.9
+
+	candidates := []Candidate{
+		{SpecialistID: "a", BookingsThatDay: 3, LastAssignedAt: &later, Rating: &good},
+		{SpecialistID: "b", BookingsThatDay: 1, LastAssignedAt: &earlier},
+		{SpecialistID: "c", BookingsThatDay: 1, LastAssignedAt: &later, Rating: &best},
+		{SpecialistID: "d", BookingsThatDay: 2, Rating: &good},
+	}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: RoundRobin, want: []string{"d", "b", "a", "c"}},
+		{name: LeastBooked, want: []string{"b", "c", "d", "a"}},
+		{name: HighestRated, want: []string{"c", "d", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, ok := ByName(tt.name)
+			if !ok {
+				t.Fatalf("ByName(%s) found no strategy", tt.name)
+			}
+
+			var got []string
+			for _, candidate := range strategy.Rank(candidates) {
+				got = append(got, candidate.SpecialistID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rank() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if candidates[0].SpecialistID != "a" || candidates[3].SpecialistID != "d" {
+		t.Error("Rank() reordered its input")
+	}
+}
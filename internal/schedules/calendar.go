@@ -0,0 +1,202 @@
+package schedules
+
+import (
+	"fmt"
+	"time"
+
+	"booking-service/internal/store/business_accounts"
+	"booking-service/internal/store/specialists"
+)
+
+const (
+	clockLayout = "15:04"
+	dateLayout  = "2006-01-02"
+)
+
+// TimeRange is a part of a day, expressed as offsets from midnight.
+type TimeRange struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// WeeklyHours holds the opening intervals of every weekday. Days without intervals are closed.
+type WeeklyHours map[time.Weekday][]TimeRange
+
+// Calendar describes when a business is open: weekly hours plus date-specific overrides,
+// interpreted in the business timezone.
+type Calendar struct {
+	Location  *time.Location
+	Weekly    WeeklyHours
+	Overrides map[string][]TimeRange
+	// Staff narrows the opening hours to when one specialist works, nil for the business as a whole.
+	Staff *StaffHours
+}
+
+// StaffHours is when a specialist works within the opening hours of the business.
+type StaffHours struct {
+	// Weekly are the shifts of the specialist; without any, the specialist works whenever the business is open.
+	Weekly  WeeklyHours
+	Breaks  WeeklyHours
+	TimeOff []Interval
+}
+
+// NewCalendar builds a calendar out of the working hours stored for a business account.
+func NewCalendar(hours *business_accounts.WorkingHours) (*Calendar, error) {
+	loc, err := time.LoadLocation(hours.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", hours.Timezone, err)
+	}
+
+	weekly, err := parseWeekly(hours.Weekly)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &Calendar{
+		Location:  loc,
+		Weekly:    weekly,
+		Overrides: make(map[string][]TimeRange, len(hours.Overrides)),
+	}
+
+	for _, override := range hours.Overrides {
+		ranges := make([]TimeRange, 0, len(override.Intervals))
+		if !override.Closed {
+			for _, interval := range override.Intervals {
+				r, err := ParseTimeInterval(interval)
+				if err != nil {
+					return nil, err
+				}
+				ranges = append(ranges, r)
+			}
+		}
+		calendar.Overrides[override.Date] = ranges
+	}
+
+	return calendar, nil
+}
+
+// NewStaffHours builds the working time of a specialist out of their stored schedule.
+func NewStaffHours(schedule *specialists.Schedule) (*StaffHours, error) {
+	weekly, err := parseWeekly(schedule.Weekly)
+	if err != nil {
+		return nil, err
+	}
+	breaks, err := parseWeekly(schedule.Breaks)
+	if err != nil {
+		return nil, err
+	}
+
+	staff := &StaffHours{Weekly: weekly, Breaks: breaks}
+	for _, timeOff := range schedule.TimeOff {
+		staff.TimeOff = append(staff.TimeOff, Interval{Start: timeOff.StartTime, End: timeOff.EndTime})
+	}
+
+	return staff, nil
+}
+
+// WithStaff returns a copy of the calendar narrowed to the working time of a specialist.
+func (c *Calendar) WithStaff(staff *StaffHours) *Calendar {
+	narrowed := *c
+	narrowed.Staff = staff
+	return &narrowed
+}
+
+// Expand returns the opening intervals between from and to. An override replaces the weekly hours of its date.
+// With staff hours, only the time the specialist works is left. Adjacent intervals are merged, so an
+// appointment may span them.
+func (c *Calendar) Expand(from, to time.Time) []Interval {
+	open := Merge(Clip(c.expandDays(from, to, func(day time.Time) []TimeRange {
+		if ranges, ok := c.Overrides[day.Format(dateLayout)]; ok {
+			return ranges
+		}
+		return c.Weekly[day.Weekday()]
+	}), from, to))
+	if c.Staff == nil {
+		return open
+	}
+
+	if c.Staff.hasShifts() {
+		shifts := c.expandDays(from, to, func(day time.Time) []TimeRange { return c.Staff.Weekly[day.Weekday()] })
+		open = Intersect(open, Merge(shifts))
+	}
+	breaks := c.expandDays(from, to, func(day time.Time) []TimeRange { return c.Staff.Breaks[day.Weekday()] })
+	return Merge(Subtract(open, append(breaks, c.Staff.TimeOff...)))
+}
+
+// expandDays places the day ranges returned by rangesOf on every day between from and to.
+func (c *Calendar) expandDays(from, to time.Time, rangesOf func(day time.Time) []TimeRange) []Interval {
+	var intervals []Interval
+	for day := startOfDay(from, c.Location); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, r := range rangesOf(day) {
+			intervals = append(intervals, Interval{Start: atOffset(day, r.Start), End: atOffset(day, r.End)})
+		}
+	}
+	return intervals
+}
+
+func (s *StaffHours) hasShifts() bool {
+	for _, ranges := range s.Weekly {
+		if len(ranges) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether the business is open during the whole [start, end) window.
+func (c *Calendar) Contains(start, end time.Time) bool {
+	open := c.Expand(start, end)
+	return len(open) == 1 && open[0].Start.Equal(start) && open[0].End.Equal(end)
+}
+
+// Date returns midnight of the given calendar date in the calendar's timezone.
+func (c *Calendar) Date(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, c.Location)
+}
+
+// parseWeekly groups weekly intervals by weekday.
+func parseWeekly(intervals []business_accounts.WorkingInterval) (WeeklyHours, error) {
+	weekly := make(WeeklyHours, 7)
+	for _, interval := range intervals {
+		r, err := ParseTimeInterval(interval.TimeInterval)
+		if err != nil {
+			return nil, err
+		}
+		weekly[interval.Weekday] = append(weekly[interval.Weekday], r)
+	}
+	return weekly, nil
+}
+
+// ParseClock parses a wall-clock "HH:MM" time into an offset from midnight.
+func ParseClock(s string) (time.Duration, error) {
+	t, err := time.Parse(clockLayout, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func ParseTimeInterval(interval business_accounts.TimeInterval) (TimeRange, error) {
+	start, err := ParseClock(interval.StartTime)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	end, err := ParseClock(interval.EndTime)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	if end <= start {
+		return TimeRange{}, fmt.Errorf("interval %s-%s must end after it starts", interval.StartTime, interval.EndTime)
+	}
+	return TimeRange{Start: start, End: end}, nil
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// atOffset builds the wall-clock time offset from midnight of day, so DST changes do not shift opening hours.
+func atOffset(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, int(offset/time.Minute), 0, 0, day.Location())
+}
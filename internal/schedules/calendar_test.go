@@ -0,0 +1,182 @@
+package schedules
+
+import (
+	"testing"
+	"time"
+
+	"booking-service/internal/store/business_accounts"
+	"booking-service/internal/store/specialists"
+)
+
+func TestCalendar_Expand(t *testing.T) {
+	calendar, err := NewCalendar(&business_accounts.WorkingHours{
+		Timezone: "UTC",
+		Weekly: []business_accounts.WorkingInterval{
+			{Weekday: time.Monday, TimeInterval: business_accounts.TimeInterval{StartTime: "09:00", EndTime: "18:00"}},
+			{Weekday: time.Tuesday, TimeInterval: business_accounts.TimeInterval{StartTime: "09:00", EndTime: "18:00"}},
+		},
+		Overrides: []business_accounts.HoursOverride{
+			{Date: "2030-03-04", Closed: true},
+			{Date: "2030-03-05", Intervals: []business_accounts.TimeInterval{{StartTime: "10:00", EndTime: "12:00"}}},
+			{Date: "2030-03-06", Intervals: []business_accounts.TimeInterval{{StartTime: "11:00", EndTime: "13:00"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewCalendar() error = %v", err)
+	}
+
+	// Monday is a holiday, Tuesday is a short day and Wednesday has an extra opening.
+	from := at(0, 0)
+	got := calendar.Expand(from, from.AddDate(0, 0, 3))
+
+	want := []Interval{
+		{Start: at(10, 0).AddDate(0, 0, 1), End: at(12, 0).AddDate(0, 0, 1)},
+		{Start: at(11, 0).AddDate(0, 0, 2), End: at(13, 0).AddDate(0, 0, 2)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expand() returned %d intervals, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("interval %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCalendar_ExpandWeekly(t *testing.T) {
+	calendar, err := NewCalendar(&business_accounts.WorkingHours{
+		Timezone: "UTC",
+		Weekly: []business_accounts.WorkingInterval{
+			{Weekday: time.Monday, TimeInterval: business_accounts.TimeInterval{StartTime: "09:00", EndTime: "12:00"}},
+			{Weekday: time.Monday, TimeInterval: business_accounts.TimeInterval{StartTime: "13:00", EndTime: "17:00"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewCalendar() error = %v", err)
+	}
+
+	// 2030-03-04 is a Monday; Tuesday has no hours and must stay closed.
+	from := at(0, 0)
+	got := calendar.Expand(from, from.AddDate(0, 0, 2))
+
+	if len(got) != 2 {
+		t.Fatalf("Expand() returned %d intervals, want 2: %v", len(got), got)
+	}
+	if !got[0].Start.Equal(at(9, 0)) || !got[0].End.Equal(at(12, 0)) || !got[1].Start.Equal(at(13, 0)) || !got[1].End.Equal(at(17, 0)) {
+		t.Errorf("unexpected intervals: %v", got)
+	}
+}
+
+func TestCalendar_ExpandInTimezone(t *testing.T) {
+	calendar, err := NewCalendar(&business_accounts.WorkingHours{
+		Timezone: "Etc/GMT-2",
+		Weekly: []business_accounts.WorkingInterval{
+			{Weekday: time.Monday, TimeInterval: business_accounts.TimeInterval{StartTime: "09:00", EndTime: "10:00"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewCalendar() error = %v", err)
+	}
+
+	from := calendar.Date(at(0, 0))
+	got := calendar.Expand(from, from.AddDate(0, 0, 1))
+
+	if len(got) != 1 || !got[0].Start.Equal(at(7, 0)) {
+		t.Errorf("expected opening at 07:00 UTC, got %v", got)
+	}
+}
+
+func TestCalendar_Contains(t *testing.T) {
+	calendar, err := NewCalendar(&business_accounts.WorkingHours{
+		Timezone: "UTC",
+		Weekly: []business_accounts.WorkingInterval{
+			{Weekday: time.Monday, TimeInterval: business_accounts.TimeInterval{StartTime: "09:00", EndTime: "13:00"}},
+			{Weekday: time.Monday, TimeInterval: business_accounts.TimeInterval{StartTime: "13:00", EndTime: "15:00"}},
+			{Weekday: time.Monday, TimeInterval: business_accounts.TimeInterval{StartTime: "16:00", EndTime: "18:00"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewCalendar() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       bool
+	}{
+		{name: "within an interval", start: at(9, 0), end: at(10, 0), want: true},
+		{name: "across adjacent intervals", start: at(12, 30), end: at(13, 30), want: true},
+		{name: "across a break", start: at(14, 30), end: at(16, 30), want: false},
+		{name: "before opening", start: at(8, 30), end: at(9, 30), want: false},
+		{name: "on a closed day", start: at(10, 0).AddDate(0, 0, 1), end: at(11, 0).AddDate(0, 0, 1), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendar.Contains(tt.start, tt.end); got != tt.want {
+				t.Errorf("Contains(%s, %s) = %v, want %v", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendar_WithStaff(t *testing.T) {
+	calendar, err := NewCalendar(&business_accounts.WorkingHours{
+		Timezone: "UTC",
+		Weekly: []business_accounts.WorkingInterval{
+			{Weekday: time.Monday, TimeInterval: business_accounts.TimeInterval{StartTime: "09:00", EndTime: "18:00"}},
+			{Weekday: time.Tuesday, TimeInterval: business_accounts.TimeInterval{StartTime: "09:00", EndTime: "18:00"}},
+			{Weekday: time.Wednesday, TimeInterval: business_accounts.TimeInterval{StartTime: "09:00", EndTime: "18:00"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewCalendar() error = %v", err)
+	}
+
+	// Shifts reaching past closing time are cut to the business hours; Wednesday is not a shift day.
+	staff, err := NewStaffHours(&specialists.Schedule{
+		Weekly: []business_accounts.WorkingInterval{
+			{Weekday: time.Monday, TimeInterval: business_accounts.TimeInterval{StartTime: "12:00", EndTime: "20:00"}},
+			{Weekday: time.Tuesday, TimeInterval: business_accounts.TimeInterval{StartTime: "08:00", EndTime: "17:00"}},
+		},
+		Breaks: []business_accounts.WorkingInterval{
+			{Weekday: time.Monday, TimeInterval: business_accounts.TimeInterval{StartTime: "14:00", EndTime: "14:30"}},
+		},
+		TimeOff: []*specialists.TimeOff{
+			{StartTime: at(12, 0).AddDate(0, 0, 1), EndTime: at(0, 0).AddDate(0, 0, 3)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewStaffHours() error = %v", err)
+	}
+
+	from := at(0, 0)
+	got := calendar.WithStaff(staff).Expand(from, from.AddDate(0, 0, 3))
+
+	want := []Interval{
+		{Start: at(12, 0), End: at(14, 0)},
+		{Start: at(14, 30), End: at(18, 0)},
+		{Start: at(9, 0).AddDate(0, 0, 1), End: at(12, 0).AddDate(0, 0, 1)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expand() returned %d intervals, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("interval %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if calendar.Staff != nil {
+		t.Error("WithStaff() modified the business calendar")
+	}
+}
+
+func TestParseTimeInterval(t *testing.T) {
+	if _, err := ParseTimeInterval(business_accounts.TimeInterval{StartTime: "18:00", EndTime: "09:00"}); err == nil {
+		t.Error("expected an error for an interval ending before it starts")
+	}
+	if _, err := ParseTimeInterval(business_accounts.TimeInterval{StartTime: "9am", EndTime: "18:00"}); err == nil {
+		t.Error("expected an error for a malformed time")
+	}
+}
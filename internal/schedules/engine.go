@@ -0,0 +1,224 @@
+package schedules
+
+import (
+	"sort"
+	"time"
+)
+
+// Interval is a half-open time range [Start, End).
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+type Slot struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	// SpotsLeft is how many customers can still book the slot.
+	SpotsLeft int `json:"spots_left"`
+}
+
+// Session is an already booked run of a group class that may still have spots left.
+type Session struct {
+	Interval
+	SpotsLeft int
+}
+
+// Clip cuts intervals to [from, to) and drops the ones falling outside of it.
+func Clip(intervals []Interval, from, to time.Time) []Interval {
+	clipped := make([]Interval, 0, len(intervals))
+	for _, in := range intervals {
+		if in.Start.Before(from) {
+			in.Start = from
+		}
+		if in.End.After(to) {
+			in.End = to
+		}
+		if in.Start.Before(in.End) {
+			clipped = append(clipped, in)
+		}
+	}
+	return clipped
+}
+
+// Merge joins overlapping and adjacent intervals and returns them ordered by start time.
+func Merge(intervals []Interval) []Interval {
+	sorted := append([]Interval(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	merged := make([]Interval, 0, len(sorted))
+	for _, in := range sorted {
+		if last := len(merged) - 1; last >= 0 && !in.Start.After(merged[last].End) {
+			if in.End.After(merged[last].End) {
+				merged[last].End = in.End
+			}
+			continue
+		}
+		merged = append(merged, in)
+	}
+	return merged
+}
+
+// Subtract removes the busy intervals from base and returns what is left, ordered by start time.
+func Subtract(base, busy []Interval) []Interval {
+	sortedBusy := append([]Interval(nil), busy...)
+	sort.Slice(sortedBusy, func(i, j int) bool { return sortedBusy[i].Start.Before(sortedBusy[j].Start) })
+
+	var free []Interval
+	for _, in := range base {
+		cursor := in.Start
+		for _, b := range sortedBusy {
+			if !b.End.After(cursor) || !b.Start.Before(in.End) {
+				continue
+			}
+			if b.Start.After(cursor) {
+				free = append(free, Interval{Start: cursor, End: b.Start})
+			}
+			if b.End.After(cursor) {
+				cursor = b.End
+			}
+		}
+		if cursor.Before(in.End) {
+			free = append(free, Interval{Start: cursor, End: in.End})
+		}
+	}
+
+	sort.Slice(free, func(i, j int) bool { return free[i].Start.Before(free[j].Start) })
+	return free
+}
+
+// Intersect returns the time covered by both a and b, ordered by start time. Both must be merged and ordered.
+func Intersect(a, b []Interval) []Interval {
+	var common []Interval
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
+		}
+		if b[j].End.Before(end) {
+			end = b[j].End
+		}
+		if start.Before(end) {
+			common = append(common, Interval{Start: start, End: end})
+		}
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return common
+}
+
+// SlotRules describe which slots of a service can be offered.
+type SlotRules struct {
+	Duration time.Duration
+	// Step aligns slot starts, e.g. to every 15 minutes, counted from midnight in Location (UTC if nil), so
+	// slots start on the hour in zones like +05:30 too.
+	Step     time.Duration
+	Location *time.Location
+	// BufferBefore and BufferAfter must stay clear of busy time around the appointment. They may fall outside
+	// of the open intervals, only the appointment itself has to fit in.
+	BufferBefore time.Duration
+	BufferAfter  time.Duration
+	// Slots starting before NotBefore or after NotAfter are skipped. A zero NotAfter means no limit.
+	NotBefore time.Time
+	NotAfter  time.Time
+	// Capacity is the number of customers one slot takes, 1 for one-to-one appointments.
+	Capacity int
+}
+
+// Slots splits open intervals into bookable slots that do not collide with busy intervals, buffers included.
+func Slots(open, busy []Interval, rules SlotRules) []Slot {
+	if rules.Duration <= 0 || rules.Step <= 0 {
+		return nil
+	}
+	busy = Merge(busy)
+
+	slots := make([]Slot, 0)
+	for _, in := range open {
+		start := alignUp(in.Start, rules.Step, rules.Location)
+		for ; !start.Add(rules.Duration).After(in.End); start = start.Add(rules.Step) {
+			if start.Before(rules.NotBefore) {
+				continue
+			}
+			if !rules.NotAfter.IsZero() && start.After(rules.NotAfter) {
+				break
+			}
+			end := start.Add(rules.Duration)
+			if overlapsAny(busy, Interval{Start: start.Add(-rules.BufferBefore), End: end.Add(rules.BufferAfter)}) {
+				continue
+			}
+			slots = append(slots, Slot{StartTime: start, EndTime: end, SpotsLeft: max(rules.Capacity, 1)})
+		}
+	}
+	return slots
+}
+
+// ClassSlots returns the slots of a group class: the free slots, which would start a new session, and the booked
+// sessions that still have spots left. Busy intervals must include the sessions, so new ones never overlap them.
+func ClassSlots(open, busy []Interval, sessions []Session, rules SlotRules) []Slot {
+	slots := Slots(open, busy, rules)
+	for _, session := range sessions {
+		if session.SpotsLeft <= 0 || session.Start.Before(rules.NotBefore) {
+			continue
+		}
+		if !rules.NotAfter.IsZero() && session.Start.After(rules.NotAfter) {
+			continue
+		}
+		if !containedIn(open, session.Interval) {
+			continue
+		}
+		slots = append(slots, Slot{StartTime: session.Start, EndTime: session.End, SpotsLeft: session.SpotsLeft})
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool { return slots[i].StartTime.Before(slots[j].StartTime) })
+	return slots
+}
+
+// UnionSlots joins the slots of several specialists into one list ordered by start time. A time more than one of
+// them is free for is listed once, with their spots added up.
+func UnionSlots(slotSets ...[]Slot) []Slot {
+	byStart := make(map[int64]int)
+	union := make([]Slot, 0)
+	for _, slots := range slotSets {
+		for _, slot := range slots {
+			if i, ok := byStart[slot.StartTime.UnixNano()]; ok {
+				union[i].SpotsLeft += slot.SpotsLeft
+				continue
+			}
+			byStart[slot.StartTime.UnixNano()] = len(union)
+			union = append(union, slot)
+		}
+	}
+
+	sort.SliceStable(union, func(i, j int) bool { return union[i].StartTime.Before(union[j].StartTime) })
+	return union
+}
+
+// containedIn reports whether in lies within one of the intervals.
+func containedIn(intervals []Interval, in Interval) bool {
+	for _, candidate := range intervals {
+		if !in.Start.Before(candidate.Start) && !in.End.After(candidate.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// alignUp returns the first step boundary at or after t, counting steps from midnight in loc.
+func alignUp(t time.Time, step time.Duration, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if rem := t.Sub(startOfDay(t, loc)) % step; rem != 0 {
+		t = t.Add(step - rem)
+	}
+	return t
+}
+
+// overlapsAny reports whether in overlaps one of the merged, ordered intervals.
+func overlapsAny(merged []Interval, in Interval) bool {
+	i := sort.Search(len(merged), func(i int) bool { return merged[i].End.After(in.Start) })
+	return i < len(merged) && merged[i].Start.Before(in.End)
+}
@@ -0,0 +1,218 @@
+package schedules
+
+import (
+	"testing"
+	"time"
+)
+
+func at(hour, minute int) time.Time {
+	return time.Date(2030, time.March, 4, hour, minute, 0, 0, time.UTC)
+}
+
+func TestSubtract(t *testing.T) {
+	base := []Interval{{Start: at(9, 0), End: at(18, 0)}}
+	busy := []Interval{
+		{Start: at(13, 0), End: at(14, 0)},
+		{Start: at(8, 0), End: at(10, 0)},
+		{Start: at(13, 30), End: at(15, 0)},
+	}
+
+	got := Subtract(base, busy)
+	want := []Interval{
+		{Start: at(10, 0), End: at(13, 0)},
+		{Start: at(15, 0), End: at(18, 0)},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Subtract() returned %d intervals, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("interval %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	a := []Interval{
+		{Start: at(9, 0), End: at(12, 0)},
+		{Start: at(13, 0), End: at(18, 0)},
+	}
+	b := []Interval{
+		{Start: at(8, 0), End: at(10, 0)},
+		{Start: at(11, 0), End: at(14, 0)},
+		{Start: at(15, 0), End: at(16, 0)},
+		{Start: at(18, 0), End: at(19, 0)},
+	}
+
+	got := Intersect(a, b)
+	want := []Interval{
+		{Start: at(9, 0), End: at(10, 0)},
+		{Start: at(11, 0), End: at(12, 0)},
+		{Start: at(13, 0), End: at(14, 0)},
+		{Start: at(15, 0), End: at(16, 0)},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Intersect() returned %d intervals, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("interval %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if got := Intersect(a, nil); len(got) != 0 {
+		t.Errorf("Intersect() with nothing = %v, want none", got)
+	}
+}
+
+func TestSlots(t *testing.T) {
+	open := []Interval{{Start: at(10, 10), End: at(11, 30)}}
+
+	got := Slots(open, nil, SlotRules{Duration: 30 * time.Minute, Step: 15 * time.Minute})
+	wantStarts := []time.Time{at(10, 15), at(10, 30), at(10, 45), at(11, 0)}
+
+	if len(got) != len(wantStarts) {
+		t.Fatalf("Slots() returned %d slots, want %d: %v", len(got), len(wantStarts), got)
+	}
+	for i, start := range wantStarts {
+		if !got[i].StartTime.Equal(start) {
+			t.Errorf("slot %d starts at %s, want %s", i, got[i].StartTime, start)
+		}
+		if !got[i].EndTime.Equal(start.Add(30 * time.Minute)) {
+			t.Errorf("slot %d ends at %s, want %s", i, got[i].EndTime, start.Add(30*time.Minute))
+		}
+	}
+}
+
+func TestSlots_InLocation(t *testing.T) {
+	india, nepal := time.FixedZone("IST", 5*3600+30*60), time.FixedZone("NPT", 5*3600+45*60)
+	local := func(loc *time.Location, hour, minute int) time.Time {
+		return time.Date(2030, time.March, 4, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name       string
+		loc        *time.Location
+		step       time.Duration
+		open       Interval
+		wantStarts []time.Time
+	}{
+		{name: "half hour offset", loc: india, step: time.Hour,
+			open:       Interval{Start: local(india, 9, 0).UTC(), End: local(india, 11, 0).UTC()},
+			wantStarts: []time.Time{local(india, 9, 0), local(india, 10, 0)}},
+		{name: "quarter hour offset", loc: nepal, step: 30 * time.Minute,
+			open:       Interval{Start: local(nepal, 9, 10).UTC(), End: local(nepal, 11, 0).UTC()},
+			wantStarts: []time.Time{local(nepal, 9, 30), local(nepal, 10, 0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Slots([]Interval{tt.open}, nil, SlotRules{Duration: time.Hour, Step: tt.step, Location: tt.loc})
+
+			if len(got) != len(tt.wantStarts) {
+				t.Fatalf("Slots() returned %d slots, want %d: %v", len(got), len(tt.wantStarts), got)
+			}
+			for i, start := range tt.wantStarts {
+				if !got[i].StartTime.Equal(start) {
+					t.Errorf("slot %d starts at %s, want %s", i, got[i].StartTime.In(tt.loc), start)
+				}
+			}
+		})
+	}
+}
+
+func TestSlots_Rules(t *testing.T) {
+	open := []Interval{{Start: at(9, 0), End: at(13, 0)}}
+	busy := []Interval{{Start: at(11, 0), End: at(11, 30)}}
+
+	tests := []struct {
+		name       string
+		rules      SlotRules
+		wantStarts []time.Time
+	}{
+		{
+			name:       "busy time is skipped",
+			rules:      SlotRules{Duration: time.Hour, Step: 30 * time.Minute},
+			wantStarts: []time.Time{at(9, 0), at(9, 30), at(10, 0), at(11, 30), at(12, 0)},
+		},
+		{
+			name: "buffers keep clear of busy time",
+			rules: SlotRules{Duration: time.Hour, Step: 30 * time.Minute,
+				BufferBefore: 15 * time.Minute, BufferAfter: 15 * time.Minute},
+			wantStarts: []time.Time{at(9, 0), at(9, 30), at(12, 0)},
+		},
+		{
+			name: "lead time and horizon",
+			rules: SlotRules{Duration: time.Hour, Step: 30 * time.Minute,
+				NotBefore: at(9, 15), NotAfter: at(11, 30)},
+			wantStarts: []time.Time{at(9, 30), at(10, 0), at(11, 30)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Slots(open, busy, tt.rules)
+			if len(got) != len(tt.wantStarts) {
+				t.Fatalf("Slots() returned %d slots, want %d: %v", len(got), len(tt.wantStarts), got)
+			}
+			for i, start := range tt.wantStarts {
+				if !got[i].StartTime.Equal(start) {
+					t.Errorf("slot %d starts at %s, want %s", i, got[i].StartTime, start)
+				}
+			}
+		})
+	}
+}
+
+func TestClassSlots(t *testing.T) {
+	open := []Interval{{Start: at(9, 0), End: at(13, 0)}}
+	sessions := []Session{
+		{Interval: Interval{Start: at(9, 15), End: at(10, 15)}, SpotsLeft: 3},
+		{Interval: Interval{Start: at(11, 0), End: at(12, 0)}, SpotsLeft: 0},
+	}
+	busy := []Interval{sessions[0].Interval, sessions[1].Interval}
+
+	got := ClassSlots(open, busy, sessions, SlotRules{Duration: time.Hour, Step: 30 * time.Minute, Capacity: 8})
+	want := []Slot{
+		{StartTime: at(9, 15), EndTime: at(10, 15), SpotsLeft: 3},
+		{StartTime: at(12, 0), EndTime: at(13, 0), SpotsLeft: 8},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ClassSlots() returned %d slots, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].StartTime.Equal(want[i].StartTime) || got[i].SpotsLeft != want[i].SpotsLeft {
+			t.Errorf("slot %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestUnionSlots(t *testing.T) {
+	jane := []Slot{
+		{StartTime: at(9, 0), EndTime: at(10, 0), SpotsLeft: 1},
+		{StartTime: at(11, 0), EndTime: at(12, 0), SpotsLeft: 1},
+	}
+	john := []Slot{
+		{StartTime: at(8, 0), EndTime: at(9, 0), SpotsLeft: 1},
+		{StartTime: at(11, 0), EndTime: at(12, 0), SpotsLeft: 1},
+	}
+
+	got := UnionSlots(jane, john, nil)
+	want := []Slot{
+		{StartTime: at(8, 0), EndTime: at(9, 0), SpotsLeft: 1},
+		{StartTime: at(9, 0), EndTime: at(10, 0), SpotsLeft: 1},
+		{StartTime: at(11, 0), EndTime: at(12, 0), SpotsLeft: 2},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("UnionSlots() returned %d slots, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].StartTime.Equal(want[i].StartTime) || got[i].SpotsLeft != want[i].SpotsLeft {
+			t.Errorf("slot %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
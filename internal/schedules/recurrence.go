@@ -0,0 +1,78 @@
+package schedules
+
+import "time"
+
+// Period is the unit a recurrence repeats in.
+type Period int
+
+const (
+	Daily Period = iota + 1
+	Weekly
+	Monthly
+)
+
+// Recurrence repeats every Interval periods, ending after Count occurrences or at Until, whichever comes first.
+type Recurrence struct {
+	Period   Period
+	Interval int
+	Count    int
+	Until    *time.Time
+}
+
+// Occurrences expands a recurrence starting at start into at most limit occurrence starts. Every occurrence
+// keeps the wall-clock time of start in loc, so a weekly 18:00 class stays at 18:00 across DST changes.
+// Monthly occurrences falling on a day the month does not have (e.g. the 31st) are skipped.
+func Occurrences(start time.Time, rec Recurrence, loc *time.Location, limit int) []time.Time {
+	interval := rec.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+
+	first := start.In(loc)
+	clock := first.Sub(startOfDay(first, loc))
+
+	var occurrences []time.Time
+	for i := 0; len(occurrences) < limit; i++ {
+		var day time.Time
+		switch rec.Period {
+		case Daily:
+			day = time.Date(first.Year(), first.Month(), first.Day()+i*interval, 0, 0, 0, 0, loc)
+		case Weekly:
+			day = time.Date(first.Year(), first.Month(), first.Day()+7*i*interval, 0, 0, 0, 0, loc)
+		case Monthly:
+			day = time.Date(first.Year(), first.Month()+time.Month(i*interval), first.Day(), 0, 0, 0, 0, loc)
+			if day.Day() != first.Day() {
+				continue
+			}
+		default:
+			return nil
+		}
+
+		occurrence := atOffset(day, clock)
+		if rec.Until != nil && occurrence.After(*rec.Until) {
+			break
+		}
+		occurrences = append(occurrences, occurrence)
+		if rec.Count > 0 && len(occurrences) == rec.Count {
+			break
+		}
+	}
+	return occurrences
+}
+
+// Shift moves t by the wall-clock change in loc that turns from into to: the same number of calendar days
+// and the same change of the time of day. Used to move the rest of a series along with one occurrence.
+func Shift(t, from, to time.Time, loc *time.Location) time.Time {
+	from, to, t = from.In(loc), to.In(loc), t.In(loc)
+	fromDay, toDay, day := startOfDay(from, loc), startOfDay(to, loc), startOfDay(t, loc)
+
+	days := civilDays(toDay) - civilDays(fromDay)
+	clock := t.Sub(day) + to.Sub(toDay) - from.Sub(fromDay)
+
+	return atOffset(time.Date(day.Year(), day.Month(), day.Day()+days, 0, 0, 0, 0, loc), clock)
+}
+
+// civilDays numbers calendar days independently of their length in the timezone.
+func civilDays(day time.Time) int {
+	return int(time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
@@ -0,0 +1,94 @@
+package schedules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOccurrences(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	until := time.Date(2030, time.March, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		rec   Recurrence
+		loc   *time.Location
+		want  []time.Time
+	}{
+		{
+			name:  "daily until",
+			start: at(10, 0),
+			rec:   Recurrence{Period: Daily, Interval: 1, Until: &until},
+			loc:   time.UTC,
+			want: []time.Time{
+				at(10, 0),
+				at(10, 0).AddDate(0, 0, 1),
+				at(10, 0).AddDate(0, 0, 2),
+				at(10, 0).AddDate(0, 0, 3),
+			},
+		},
+		{
+			name:  "every other week keeps the wall clock across DST",
+			start: time.Date(2030, time.March, 18, 18, 0, 0, 0, berlin),
+			rec:   Recurrence{Period: Weekly, Interval: 2, Count: 2},
+			loc:   berlin,
+			want: []time.Time{
+				time.Date(2030, time.March, 18, 18, 0, 0, 0, berlin),
+				time.Date(2030, time.April, 1, 18, 0, 0, 0, berlin),
+			},
+		},
+		{
+			name:  "monthly skips months without the day",
+			start: time.Date(2030, time.January, 31, 9, 0, 0, 0, time.UTC),
+			rec:   Recurrence{Period: Monthly, Count: 3},
+			loc:   time.UTC,
+			want: []time.Time{
+				time.Date(2030, time.January, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2030, time.March, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2030, time.May, 31, 9, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Occurrences(tt.start, tt.rec, tt.loc, 52)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Occurrences() returned %d occurrences, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i := range tt.want {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOccurrences_Limit(t *testing.T) {
+	rec := Recurrence{Period: Daily, Count: 100}
+	if got := Occurrences(at(10, 0), rec, time.UTC, 5); len(got) != 5 {
+		t.Errorf("expected the limit to cap occurrences at 5, got %d", len(got))
+	}
+}
+
+func TestShift(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving Monday 18:00 to Tuesday 19:30 moves a later Monday, across DST, to its Tuesday 19:30
+	from := time.Date(2030, time.March, 18, 18, 0, 0, 0, berlin)
+	to := time.Date(2030, time.March, 19, 19, 30, 0, 0, berlin)
+	later := time.Date(2030, time.April, 1, 18, 0, 0, 0, berlin)
+
+	want := time.Date(2030, time.April, 2, 19, 30, 0, 0, berlin)
+	if got := Shift(later, from, to, berlin); !got.Equal(want) {
+		t.Errorf("Shift() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package bookings
+
+import (
+	"context"
+	"time"
+)
+
+// CalendarEntry is a booking as shown on the business calendar, with the names the front desk needs.
+type CalendarEntry struct {
+	Booking
+	CustomerName string `json:"customer_name"`
+	ServiceName  string `json:"service_name"`
+}
+
+type ListBusinessBookingsRequest struct {
+	BusinessID       string
+	From             time.Time
+	To               time.Time
+	IncludeCancelled bool
+}
+
+// ListBusinessBookings returns the bookings of a business starting within [From, To), ordered by start time.
+func (s *PgStore) ListBusinessBookings(ctx context.Context, req ListBusinessBookingsRequest) ([]*CalendarEntry, error) {
+	query := `
+		SELECT ` + qualifiedBookingColumns("b") + `,
+			COALESCE(NULLIF(TRIM(CONCAT_WS(' ', u.firstname, u.lastname)), ''), u.username, u.email, b.guest_name, ''),
+			s.name
+		FROM bookings b
+		LEFT JOIN users u ON u.id = b.user_id
+		JOIN services s ON s.id = b.service_id
+		WHERE b.business_id = $1
+			AND b.start_time >= $2
+			AND b.start_time < $3
+			AND ($4 OR b.` + activeBookingCondition + `)
+		ORDER BY b.start_time, b.id
+	`
+
+	rows, err := s.readPool.Query(ctx, query, req.BusinessID, req.From, req.To, req.IncludeCancelled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*CalendarEntry{}
+	for rows.Next() {
+		var customerName, serviceName string
+		booking, err := scanBooking(rows, &customerName, &serviceName)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &CalendarEntry{Booking: *booking, CustomerName: customerName, ServiceName: serviceName})
+	}
+
+	return entries, rows.Err()
+}
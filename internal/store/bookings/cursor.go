@@ -0,0 +1,38 @@
+package bookings
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor points at the last booking of a page. Bookings are ordered by (start_time, id),
+// so the pair identifies a position in the list even when start times repeat. Descending keeps
+// the direction of the list, a position means nothing in the other one.
+type cursor struct {
+	StartTime  time.Time `json:"s"`
+	ID         string    `json:"i"`
+	Descending bool      `json:"d,omitempty"`
+}
+
+func encodeCursor(booking *Booking, descending bool) string {
+	data, _ := json.Marshal(cursor{StartTime: booking.StartTime, ID: booking.ID, Descending: descending})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" || c.StartTime.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,32 @@
+package bookings
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	booking := &Booking{
+		ID:        "7d9f6a1e-1f0c-4a57-9a0e-3c2b1d0e9f8a",
+		StartTime: time.Date(2030, time.March, 4, 10, 30, 0, 0, time.UTC),
+	}
+
+	for _, descending := range []bool{false, true} {
+		decoded, err := decodeCursor(encodeCursor(booking, descending))
+		if err != nil {
+			t.Fatalf("decodeCursor() error = %v", err)
+		}
+		if decoded.ID != booking.ID || !decoded.StartTime.Equal(booking.StartTime) || decoded.Descending != descending {
+			t.Errorf("decodeCursor() = %+v, want id %s, start %s and descending %v", decoded, booking.ID, booking.StartTime, descending)
+		}
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, s := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		if _, err := decodeCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeCursor(%q) error = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
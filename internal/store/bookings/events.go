@@ -0,0 +1,74 @@
+package bookings
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type EventAction string
+
+const (
+	EventStatusChanged EventAction = "status_changed"
+	EventRescheduled   EventAction = "rescheduled"
+)
+
+// Event is an audit record of a change made to a booking and of the user who made it. Changes made by
+// the service itself, like expiring holds, have no actor.
+type Event struct {
+	ID          string      `json:"id"`
+	BookingID   string      `json:"booking_id"`
+	ActorUserID string      `json:"actor_user_id,omitempty"`
+	Action      EventAction `json:"action"`
+	FromStatus  Status      `json:"from_status"`
+	ToStatus    Status      `json:"to_status"`
+	FromWindow  *TimeWindow `json:"from_window,omitempty"`
+	ToWindow    *TimeWindow `json:"to_window,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+func insertEvent(ctx context.Context, tx pgx.Tx, event Event) error {
+	query := `
+		INSERT INTO booking_events (
+			id, booking_id, actor_user_id, action, from_status, to_status,
+			from_start_time, from_end_time, to_start_time, to_end_time, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)
+	`
+
+	var actorUserID *string
+	if event.ActorUserID != "" {
+		actorUserID = &event.ActorUserID
+	}
+
+	var fromStart, fromEnd, toStart, toEnd *time.Time
+	if event.FromWindow != nil {
+		fromStart, fromEnd = &event.FromWindow.StartTime, &event.FromWindow.EndTime
+	}
+	if event.ToWindow != nil {
+		toStart, toEnd = &event.ToWindow.StartTime, &event.ToWindow.EndTime
+	}
+
+	_, err := tx.Exec(ctx, query,
+		uuid.New().String(),
+		event.BookingID,
+		actorUserID,
+		event.Action,
+		event.FromStatus,
+		event.ToStatus,
+		fromStart,
+		fromEnd,
+		toStart,
+		toEnd,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record booking event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package bookings
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"booking-service/pkg/money"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// BookingItem is one of the services performed in a booking, in the order they are performed. Duration and price
+// are taken from the service, its variant and add-ons when the booking is made.
+type BookingItem struct {
+	Position        int         `json:"position"`
+	ServiceID       string      `json:"service_id"`
+	VariantID       *string     `json:"variant_id,omitempty"`
+	AddOnIDs        []string    `json:"add_on_ids,omitempty"`
+	StartTime       time.Time   `json:"start_time"`
+	EndTime         time.Time   `json:"end_time"`
+	DurationMinutes int         `json:"duration_minutes"`
+	Price           money.Money `json:"price"`
+}
+
+// setItems attaches the items to the booking together with their total price.
+func (b *Booking) setItems(items []BookingItem) {
+	if len(items) == 0 {
+		return
+	}
+	prices := make([]money.Money, 0, len(items))
+	for _, item := range items {
+		prices = append(prices, item.Price)
+	}
+	b.Items = items
+	// The services of a booking share one currency, it is checked when the booking is made
+	if total, err := money.Sum(prices...); err == nil {
+		b.TotalPrice = &total
+	}
+}
+
+// scheduleItems numbers the items and lays them out back to back from start.
+func scheduleItems(items []BookingItem, start time.Time) {
+	for i := range items {
+		items[i].Position = i + 1
+		items[i].StartTime = start
+		items[i].EndTime = start.Add(time.Duration(items[i].DurationMinutes) * time.Minute)
+		start = items[i].EndTime
+	}
+}
+
+// lastServiceID is the service performed at the end of the booking, whose buffer follows the booking.
+func lastServiceID(serviceID string, items []BookingItem) string {
+	if len(items) == 0 {
+		return serviceID
+	}
+	return items[len(items)-1].ServiceID
+}
+
+func insertItems(ctx context.Context, tx pgx.Tx, bookingID string, items []BookingItem) error {
+	query := `
+		INSERT INTO booking_items (booking_id, position, service_id, variant_id, add_on_ids, duration_minutes, price_minor, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	for _, item := range items {
+		_, err := tx.Exec(ctx, query, bookingID, item.Position, item.ServiceID, item.VariantID, item.AddOnIDs,
+			item.DurationMinutes, item.Price.Amount, item.Price.Currency)
+		if err != nil {
+			return fmt.Errorf("failed to insert booking item: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// queryer is implemented by the pool as well as by a transaction.
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// listItems returns the items of the booking laid out from its start time. Bookings of a single service made
+// before line items were recorded have none.
+func listItems(ctx context.Context, q queryer, booking *Booking) ([]BookingItem, error) {
+	query := `
+		SELECT service_id, variant_id, add_on_ids, duration_minutes, price_minor, currency
+		FROM booking_items
+		WHERE booking_id = $1
+		ORDER BY position
+	`
+
+	rows, err := q.Query(ctx, query, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []BookingItem
+	for rows.Next() {
+		var item BookingItem
+		if err := rows.Scan(&item.ServiceID, &item.VariantID, &item.AddOnIDs, &item.DurationMinutes, &item.Price.Amount, &item.Price.Currency); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	scheduleItems(items, booking.StartTime)
+	return items, nil
+}
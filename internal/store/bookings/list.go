@@ -0,0 +1,119 @@
+package bookings
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type ListScope string
+
+const (
+	ScopeAll      ListScope = ""
+	ScopeUpcoming ListScope = "upcoming"
+	ScopePast     ListScope = "past"
+)
+
+type ListBookingsRequest struct {
+	UserID   string
+	Statuses []Status
+	Scope    ListScope
+	From     *time.Time
+	To       *time.Time
+	// Descending lists the latest bookings first, which is what a history view wants.
+	Descending bool
+	Cursor     string
+	Limit      int
+}
+
+type ListBookingsResponse struct {
+	Bookings   []*Booking `json:"bookings"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// ListBookings returns a page of the user's bookings ordered by start time. Pagination is keyset based:
+// NextCursor is set when there are more bookings and is passed back unchanged to get the next page, which
+// keeps the order of the first one.
+func (s *PgStore) ListBookings(ctx context.Context, req ListBookingsRequest) (*ListBookingsResponse, error) {
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{req.UserID}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(req.Statuses) > 0 {
+		statuses := make([]string, 0, len(req.Statuses))
+		for _, status := range req.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		conditions = append(conditions, "status = ANY("+arg(statuses)+")")
+	}
+
+	now := time.Now()
+	switch req.Scope {
+	case ScopeUpcoming:
+		conditions = append(conditions, "start_time >= "+arg(now))
+	case ScopePast:
+		conditions = append(conditions, "start_time < "+arg(now))
+	}
+
+	if req.From != nil {
+		conditions = append(conditions, "start_time >= "+arg(*req.From))
+	}
+	if req.To != nil {
+		conditions = append(conditions, "start_time < "+arg(*req.To))
+	}
+
+	// A cursor continues the list it came from, in its order
+	var c *cursor
+	if req.Cursor != "" {
+		var err error
+		if c, err = decodeCursor(req.Cursor); err != nil {
+			return nil, err
+		}
+		req.Descending = c.Descending
+	}
+
+	order, comparison := "ASC", ">"
+	if req.Descending {
+		order, comparison = "DESC", "<"
+	}
+	if c != nil {
+		conditions = append(conditions, fmt.Sprintf("(start_time, id) %s (%s, %s)", comparison, arg(c.StartTime), arg(c.ID)))
+	}
+
+	// One extra row tells whether there is a next page
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY start_time ` + order + `, id ` + order + `
+		LIMIT ` + arg(req.Limit+1)
+
+	rows, err := s.readPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &ListBookingsResponse{Bookings: []*Booking{}}
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Bookings = append(result.Bookings, booking)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(result.Bookings) > req.Limit {
+		result.Bookings = result.Bookings[:req.Limit]
+		result.NextCursor = encodeCursor(result.Bookings[req.Limit-1], req.Descending)
+	}
+
+	return result, nil
+}
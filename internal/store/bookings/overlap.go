@@ -0,0 +1,94 @@
+package bookings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// exclusionViolationCode is the Postgres error code raised when the bookings_no_overlap constraint fails.
+// The constraint keys bookings by business and specialist. Bookings without a specialist take the time of the
+// business itself, one more resource next to its specialists: they only conflict with each other, never with
+// bookings of a specialist. ListBusyWindows without a specialist matches them the same way.
+const exclusionViolationCode = "23P01"
+
+// activeBookingCondition matches the bookings that occupy their time slot. It mirrors the WHERE clause
+// of the bookings_no_overlap exclusion constraint and must be kept in sync with it.
+const activeBookingCondition = `status NOT IN ('cancelled_by_customer', 'cancelled_by_business', 'expired')`
+
+var ErrBookingOverlap = errors.New("booking overlaps an existing booking")
+
+type TimeWindow struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// OverlapError is returned when a booking would overlap an active booking of the same business or specialist.
+type OverlapError struct {
+	Conflicting TimeWindow
+}
+
+func (e *OverlapError) Error() string {
+	return fmt.Sprintf("%s from %s to %s", ErrBookingOverlap,
+		e.Conflicting.StartTime.Format(time.RFC3339), e.Conflicting.EndTime.Format(time.RFC3339))
+}
+
+func (e *OverlapError) Is(target error) bool {
+	return target == ErrBookingOverlap
+}
+
+func isOverlapViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == exclusionViolationCode
+}
+
+// blockedWindow returns the time a booking from start to end takes out of the calendar: the appointment padded
+// with the buffer before its first service and the buffer after its last service.
+func blockedWindow(ctx context.Context, q queryRower, firstServiceID, lastServiceID string, start, end time.Time) (TimeWindow, error) {
+	query := `
+		SELECT
+			(SELECT buffer_before_minutes FROM services WHERE id = $1),
+			(SELECT buffer_after_minutes FROM services WHERE id = $2)
+	`
+
+	var before, after *int
+	if err := q.QueryRow(ctx, query, firstServiceID, lastServiceID).Scan(&before, &after); err != nil {
+		return TimeWindow{}, fmt.Errorf("failed to get service buffers: %w", err)
+	}
+	if before == nil || after == nil {
+		return TimeWindow{}, ErrServiceNotFound
+	}
+
+	return TimeWindow{
+		StartTime: start.Add(-time.Duration(*before) * time.Minute),
+		EndTime:   end.Add(time.Duration(*after) * time.Minute),
+	}, nil
+}
+
+// overlapError looks up the booking that made the exclusion constraint fail. The lookup goes through q, the write pool
+// or the transaction that failed, because the conflicting row may not have reached the replica yet.
+func overlapError(ctx context.Context, q queryRower, businessID string, specialistID *string, blocked TimeWindow) error {
+	query := `
+		SELECT start_time, end_time
+		FROM bookings
+		WHERE business_id = $1
+			AND specialist_id IS NOT DISTINCT FROM $2
+			AND ` + activeBookingCondition + `
+			AND tstzrange(blocked_start, blocked_end) && tstzrange($3, $4)
+		ORDER BY start_time
+		LIMIT 1
+	`
+
+	var window TimeWindow
+	err := q.QueryRow(ctx, query, businessID, specialistID, blocked.StartTime, blocked.EndTime).
+		Scan(&window.StartTime, &window.EndTime)
+	if err != nil {
+		// The conflicting booking may have been cancelled in the meantime; report the requested window instead.
+		window = blocked
+	}
+
+	return &OverlapError{Conflicting: window}
+}
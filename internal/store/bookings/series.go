@@ -0,0 +1,293 @@
+package bookings
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "daily"
+	FrequencyWeekly  Frequency = "weekly"
+	FrequencyMonthly Frequency = "monthly"
+)
+
+func StringToFrequency(s string) (Frequency, bool) {
+	switch frequency := Frequency(s); frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+		return frequency, true
+	default:
+		return "", false
+	}
+}
+
+// Recurrence is an RRULE-like pattern: every Interval days, weeks or months, ending after Count occurrences
+// or at Until, whichever comes first.
+type Recurrence struct {
+	Frequency Frequency  `json:"frequency"`
+	Interval  int        `json:"interval"`
+	Count     int        `json:"count,omitempty"`
+	Until     *time.Time `json:"until,omitempty"`
+}
+
+// SeriesScope selects the occurrences of a series a change applies to.
+type SeriesScope string
+
+const (
+	ScopeThis      SeriesScope = "this"
+	ScopeFollowing SeriesScope = "following"
+	ScopeSeries    SeriesScope = "all"
+)
+
+func StringToSeriesScope(s string) (SeriesScope, bool) {
+	switch scope := SeriesScope(s); scope {
+	case ScopeThis, ScopeFollowing, ScopeSeries:
+		return scope, true
+	default:
+		return "", false
+	}
+}
+
+// Series groups recurring bookings of the same customer and service.
+type Series struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	BusinessID   string     `json:"business_id"`
+	SpecialistID *string    `json:"specialist_id,omitempty"`
+	ServiceID    string     `json:"service_id"`
+	Recurrence   Recurrence `json:"recurrence"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type CreateSeriesRequest struct {
+	UserID       string     `json:"-"`
+	BusinessID   string     `json:"business_id"`
+	SpecialistID *string    `json:"specialist_id,omitempty"`
+	ServiceID    string     `json:"service_id"`
+	StartTime    time.Time  `json:"start_time"`
+	Recurrence   Recurrence `json:"recurrence"`
+	// Occurrences are the windows to book, expanded from the recurrence and validated by the caller.
+	Occurrences []TimeWindow `json:"-"`
+}
+
+// OccurrenceConflict is an occurrence of a series that could not be booked.
+type OccurrenceConflict struct {
+	TimeWindow
+	Reason      string      `json:"reason"`
+	Conflicting *TimeWindow `json:"conflicting,omitempty"`
+}
+
+type SeriesResult struct {
+	Series    *Series              `json:"series"`
+	Bookings  []*Booking           `json:"bookings"`
+	Conflicts []OccurrenceConflict `json:"conflicts"`
+}
+
+const seriesColumns = `id, user_id, business_id, specialist_id, service_id, frequency, interval, count, until, created_at`
+
+func scanSeries(row pgx.Row) (*Series, error) {
+	var series Series
+	err := row.Scan(
+		&series.ID,
+		&series.UserID,
+		&series.BusinessID,
+		&series.SpecialistID,
+		&series.ServiceID,
+		&series.Recurrence.Frequency,
+		&series.Recurrence.Interval,
+		&series.Recurrence.Count,
+		&series.Recurrence.Until,
+		&series.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// CreateSeries creates the series and books its occurrences. Occurrences overlapping other bookings, or
+// falling on full class sessions, are reported as conflicts and left out; when none of them can be booked, nothing is created and the series is nil.
+func (s *PgStore) CreateSeries(ctx context.Context, req CreateSeriesRequest) (*SeriesResult, error) {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO booking_series (
+			id, user_id, business_id, specialist_id, service_id, frequency, interval, count, until, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		) RETURNING ` + seriesColumns
+
+	series, err := scanSeries(tx.QueryRow(ctx, query,
+		uuid.New().String(),
+		req.UserID,
+		req.BusinessID,
+		req.SpecialistID,
+		req.ServiceID,
+		req.Recurrence.Frequency,
+		req.Recurrence.Interval,
+		req.Recurrence.Count,
+		req.Recurrence.Until,
+		time.Now(),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create booking series: %w", err)
+	}
+
+	result := &SeriesResult{Bookings: make([]*Booking, 0, len(req.Occurrences)), Conflicts: make([]OccurrenceConflict, 0)}
+	for _, occurrence := range req.Occurrences {
+		booking, err := s.insertOccurrence(ctx, tx, CreateBookingRequest{
+			UserID:       req.UserID,
+			SeriesID:     &series.ID,
+			BusinessID:   req.BusinessID,
+			SpecialistID: req.SpecialistID,
+			ServiceID:    req.ServiceID,
+			StartTime:    occurrence.StartTime,
+			EndTime:      occurrence.EndTime,
+		})
+		var overlapErr *OverlapError
+		switch {
+		case errors.As(err, &overlapErr):
+			result.Conflicts = append(result.Conflicts, OccurrenceConflict{
+				TimeWindow:  occurrence,
+				Reason:      ErrBookingOverlap.Error(),
+				Conflicting: &overlapErr.Conflicting,
+			})
+		case errors.Is(err, ErrSessionFull), errors.Is(err, ErrAlreadyAttending):
+			result.Conflicts = append(result.Conflicts, OccurrenceConflict{TimeWindow: occurrence, Reason: err.Error()})
+		case err != nil:
+			return nil, err
+		default:
+			result.Bookings = append(result.Bookings, booking)
+		}
+	}
+
+	if len(result.Bookings) == 0 {
+		return result, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	result.Series = series
+	return result, nil
+}
+
+// insertOccurrence books one occurrence under a savepoint, so an overlap only discards that occurrence
+// and not the whole series.
+func (s *PgStore) insertOccurrence(ctx context.Context, tx pgx.Tx, req CreateBookingRequest) (*Booking, error) {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	defer savepoint.Rollback(ctx)
+
+	booking, err := s.insertBooking(ctx, savepoint, req)
+	if err != nil {
+		if errors.Is(err, ErrBookingOverlap) {
+			// Look the conflict up again once the savepoint is released, so earlier occurrences are visible
+			if rbErr := savepoint.Rollback(ctx); rbErr != nil {
+				return nil, fmt.Errorf("failed to roll back to savepoint: %w", rbErr)
+			}
+			blocked, bErr := blockedWindow(ctx, tx, req.ServiceID, lastServiceID(req.ServiceID, req.Items), req.StartTime, req.EndTime)
+			if bErr != nil {
+				return nil, bErr
+			}
+			return nil, overlapError(ctx, tx, req.BusinessID, req.SpecialistID, blocked)
+		}
+		return nil, err
+	}
+
+	if err := savepoint.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to release savepoint: %w", err)
+	}
+
+	return booking, nil
+}
+
+func (s *PgStore) GetSeries(ctx context.Context, id string) (*Series, error) {
+	query := `
+		SELECT ` + seriesColumns + `
+		FROM booking_series
+		WHERE id = $1
+	`
+
+	series, err := scanSeries(s.readPool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// ListSeriesBookings returns all bookings of a series, ordered by start time.
+func (s *PgStore) ListSeriesBookings(ctx context.Context, seriesID string) ([]*Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings
+		WHERE series_id = $1
+		ORDER BY start_time, id
+	`
+
+	rows, err := s.readPool.Query(ctx, query, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*Booking
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	return bookings, rows.Err()
+}
+
+// InScope picks the bookings of a series a change of anchor applies to. Bookings in a terminal status
+// are never changed; the whole series only covers the bookings that have not started yet, as of now.
+func InScope(series []*Booking, anchor *Booking, scope SeriesScope, now time.Time) []*Booking {
+	selected := []*Booking{anchor}
+	if scope == ScopeThis {
+		return selected
+	}
+
+	for _, booking := range series {
+		if booking.ID == anchor.ID || booking.Status.IsTerminal() {
+			continue
+		}
+		switch scope {
+		case ScopeFollowing:
+			if booking.StartTime.Before(anchor.StartTime) {
+				continue
+			}
+		case ScopeSeries:
+			if !booking.StartTime.After(now) {
+				continue
+			}
+		}
+		selected = append(selected, booking)
+	}
+
+	sort.Slice(selected, func(i, j int) bool { return selected[i].StartTime.Before(selected[j].StartTime) })
+	return selected
+}
@@ -0,0 +1,84 @@
+package bookings
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInScope(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2030, time.March, d, 10, 0, 0, 0, time.UTC) }
+	series := []*Booking{
+		{ID: "1", StartTime: day(1), Status: StatusCompleted},
+		{ID: "2", StartTime: day(8), Status: StatusConfirmed},
+		{ID: "3", StartTime: day(15), Status: StatusConfirmed},
+		{ID: "4", StartTime: day(22), Status: StatusCancelledByCustomer},
+		{ID: "5", StartTime: day(29), Status: StatusPending},
+	}
+	now := day(5)
+
+	tests := []struct {
+		name   string
+		anchor *Booking
+		scope  SeriesScope
+		want   []string
+	}{
+		{name: "this occurrence", anchor: series[2], scope: ScopeThis, want: []string{"3"}},
+		{name: "this and following", anchor: series[2], scope: ScopeFollowing, want: []string{"3", "5"}},
+		{name: "whole series", anchor: series[2], scope: ScopeSeries, want: []string{"2", "3", "5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := InScope(series, tt.anchor, tt.scope, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("InScope() returned %d bookings, want %d", len(got), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if got[i].ID != id {
+					t.Errorf("booking %d = %s, want %s", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestRescheduleOrder(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2030, time.March, d, 10, 0, 0, 0, time.UTC) }
+	starts := []time.Time{day(1), day(8), day(15)}
+	shift := func(days int) []BookingReschedule {
+		changes := make([]BookingReschedule, len(starts))
+		for i, start := range starts {
+			changes[i].StartTime = start.AddDate(0, 0, days)
+		}
+		return changes
+	}
+
+	tests := []struct {
+		name    string
+		changes []BookingReschedule
+		want    []int
+	}{
+		{name: "a week later", changes: shift(7), want: []int{2, 1, 0}},
+		{name: "a week earlier", changes: shift(-7), want: []int{0, 1, 2}},
+		{
+			name: "both directions",
+			changes: []BookingReschedule{
+				{RescheduleBookingRequest: RescheduleBookingRequest{StartTime: day(2)}},
+				{RescheduleBookingRequest: RescheduleBookingRequest{StartTime: day(7)}},
+				{RescheduleBookingRequest: RescheduleBookingRequest{StartTime: day(16)}},
+			},
+			want: []int{1, 2, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rescheduleOrder(tt.changes, starts)
+			for i, index := range tt.want {
+				if got[i] != index {
+					t.Fatalf("rescheduleOrder() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
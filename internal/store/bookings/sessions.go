@@ -0,0 +1,132 @@
+package bookings
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrSessionFull      = errors.New("class session is fully booked")
+	ErrAlreadyAttending = errors.New("customer already attends this class session")
+)
+
+// ClassSession is one run of a group class. Bookings of a service with a capacity above one share the session
+// starting at their start time, up to the capacity of the service at the time the session was created.
+type ClassSession struct {
+	ID           string    `json:"id"`
+	BusinessID   string    `json:"business_id"`
+	ServiceID    string    `json:"service_id"`
+	SpecialistID *string   `json:"specialist_id,omitempty"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	Capacity     int       `json:"capacity"`
+	// Booked counts the active bookings of the session.
+	Booked int `json:"booked"`
+}
+
+func (s ClassSession) SpotsLeft() int {
+	return max(s.Capacity-s.Booked, 0)
+}
+
+// joinSession assigns a booking of a class to the session at its start time, creating the session for the first
+// attendee. The session row stays locked until the end of the transaction, so concurrent attendees cannot
+// overbook it. For services booked one to one it returns nil.
+func joinSession(ctx context.Context, tx pgx.Tx, bookingID string, req CreateBookingRequest) (*string, error) {
+	var capacity int
+	err := tx.QueryRow(ctx, `SELECT capacity FROM services WHERE id = $1`, req.ServiceID).Scan(&capacity)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrServiceNotFound
+		}
+		return nil, fmt.Errorf("failed to get service capacity: %w", err)
+	}
+	if capacity <= 1 {
+		return nil, nil
+	}
+
+	// The no-op update makes the conflicting row lockable and returnable
+	query := `
+		INSERT INTO class_sessions (id, business_id, service_id, specialist_id, start_time, end_time, capacity, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (service_id, COALESCE(specialist_id, '00000000-0000-0000-0000-000000000000'::uuid), start_time)
+		DO UPDATE SET start_time = EXCLUDED.start_time
+		RETURNING id, capacity
+	`
+
+	var sessionID string
+	err = tx.QueryRow(ctx, query, uuid.New().String(), req.BusinessID, req.ServiceID, req.SpecialistID,
+		req.StartTime, req.EndTime, capacity, time.Now()).Scan(&sessionID, &capacity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class session: %w", err)
+	}
+
+	query = `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id IS NOT NULL AND user_id = $3)
+		FROM bookings
+		WHERE session_id = $1 AND id <> $2 AND ` + activeBookingCondition
+
+	var booked, attending int
+	var userID *string
+	if req.UserID != "" {
+		userID = &req.UserID
+	}
+	if err := tx.QueryRow(ctx, query, sessionID, bookingID, userID).Scan(&booked, &attending); err != nil {
+		return nil, fmt.Errorf("failed to count class session bookings: %w", err)
+	}
+	if attending > 0 {
+		return nil, ErrAlreadyAttending
+	}
+	if booked >= capacity {
+		return nil, ErrSessionFull
+	}
+
+	return &sessionID, nil
+}
+
+// ListSessions returns the class sessions of a business starting within [from, to), ordered by start time.
+func (s *PgStore) ListSessions(ctx context.Context, businessID string, from, to time.Time) ([]*ClassSession, error) {
+	query := `
+		SELECT cs.id, cs.business_id, cs.service_id, cs.specialist_id, cs.start_time, cs.end_time, cs.capacity,
+			COUNT(b.id)
+		FROM class_sessions cs
+		LEFT JOIN bookings b ON b.session_id = cs.id AND b.` + activeBookingCondition + `
+		WHERE cs.business_id = $1
+			AND cs.start_time >= $2
+			AND cs.start_time < $3
+		GROUP BY cs.id
+		ORDER BY cs.start_time, cs.id
+	`
+
+	rows, err := s.readPool.Query(ctx, query, businessID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*ClassSession{}
+	for rows.Next() {
+		var session ClassSession
+		err := rows.Scan(
+			&session.ID,
+			&session.BusinessID,
+			&session.ServiceID,
+			&session.SpecialistID,
+			&session.StartTime,
+			&session.EndTime,
+			&session.Capacity,
+			&session.Booked,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, rows.Err()
+}
@@ -0,0 +1,90 @@
+package bookings
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Status string
+
+const (
+	StatusPending             Status = "pending"
+	StatusConfirmed           Status = "confirmed"
+	StatusRescheduled         Status = "rescheduled"
+	StatusCancelledByCustomer Status = "cancelled_by_customer"
+	StatusCancelledByBusiness Status = "cancelled_by_business"
+	StatusCompleted           Status = "completed"
+	StatusNoShow              Status = "no_show"
+	// StatusHeld bookings reserve a freed slot for a waitlisted customer until they claim it or the hold expires.
+	StatusHeld    Status = "held"
+	StatusExpired Status = "expired"
+)
+
+var (
+	ErrBookingNotFound   = errors.New("booking not found")
+	ErrServiceNotFound   = errors.New("service not found")
+	ErrInvalidTransition = errors.New("invalid booking status transition")
+)
+
+// transitions lists, for every status, the statuses a booking is allowed to move to.
+// Statuses without an entry are terminal.
+var transitions = map[Status][]Status{
+	StatusHeld: {
+		StatusPending,
+		StatusExpired,
+		StatusCancelledByBusiness,
+	},
+	StatusPending: {
+		StatusConfirmed,
+		StatusRescheduled,
+		StatusCancelledByCustomer,
+		StatusCancelledByBusiness,
+	},
+	StatusConfirmed: {
+		StatusRescheduled,
+		StatusCancelledByCustomer,
+		StatusCancelledByBusiness,
+		StatusCompleted,
+		StatusNoShow,
+	},
+	StatusRescheduled: {
+		StatusConfirmed,
+		StatusRescheduled,
+		StatusCancelledByCustomer,
+		StatusCancelledByBusiness,
+		StatusCompleted,
+		StatusNoShow,
+	},
+}
+
+func StringToStatus(s string) (Status, bool) {
+	switch status := Status(s); status {
+	case StatusPending, StatusConfirmed, StatusRescheduled, StatusCancelledByCustomer,
+		StatusCancelledByBusiness, StatusCompleted, StatusNoShow, StatusHeld, StatusExpired:
+		return status, true
+	default:
+		return "", false
+	}
+}
+
+// CanTransition reports whether a booking in status from may be moved to status to.
+func CanTransition(from, to Status) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Status) IsTerminal() bool {
+	return len(transitions[s]) == 0
+}
+
+func (s Status) IsCancelled() bool {
+	return s == StatusCancelledByCustomer || s == StatusCancelledByBusiness
+}
+
+func transitionError(from, to Status) error {
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+}
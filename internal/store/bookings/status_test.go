@@ -0,0 +1,94 @@
+package bookings
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		name string
+		from Status
+		to   Status
+		want bool
+	}{
+		{name: "pending to confirmed", from: StatusPending, to: StatusConfirmed, want: true},
+		{name: "pending to cancelled by customer", from: StatusPending, to: StatusCancelledByCustomer, want: true},
+		{name: "pending to completed", from: StatusPending, to: StatusCompleted, want: false},
+		{name: "confirmed to completed", from: StatusConfirmed, to: StatusCompleted, want: true},
+		{name: "confirmed to no show", from: StatusConfirmed, to: StatusNoShow, want: true},
+		{name: "confirmed to pending", from: StatusConfirmed, to: StatusPending, want: false},
+		{name: "rescheduled to confirmed", from: StatusRescheduled, to: StatusConfirmed, want: true},
+		{name: "completed is terminal", from: StatusCompleted, to: StatusConfirmed, want: false},
+		{name: "cancelled is terminal", from: StatusCancelledByBusiness, to: StatusConfirmed, want: false},
+		{name: "no show is terminal", from: StatusNoShow, to: StatusCompleted, want: false},
+		{name: "held to pending", from: StatusHeld, to: StatusPending, want: true},
+		{name: "held to expired", from: StatusHeld, to: StatusExpired, want: true},
+		{name: "held to cancelled by business", from: StatusHeld, to: StatusCancelledByBusiness, want: true},
+		{name: "held to cancelled by customer", from: StatusHeld, to: StatusCancelledByCustomer, want: false},
+		{name: "held to confirmed", from: StatusHeld, to: StatusConfirmed, want: false},
+		{name: "expired is terminal", from: StatusExpired, to: StatusPending, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanTransition(tt.from, tt.to); got != tt.want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatus_IsTerminal(t *testing.T) {
+	terminal := []Status{StatusCancelledByCustomer, StatusCancelledByBusiness, StatusCompleted, StatusNoShow, StatusExpired}
+	for _, status := range terminal {
+		if !status.IsTerminal() {
+			t.Errorf("expected %s to be terminal", status)
+		}
+	}
+
+	active := []Status{StatusPending, StatusConfirmed, StatusRescheduled, StatusHeld}
+	for _, status := range active {
+		if status.IsTerminal() {
+			t.Errorf("expected %s not to be terminal", status)
+		}
+	}
+}
+
+func TestStringToStatus(t *testing.T) {
+	if status, ok := StringToStatus("no_show"); !ok || status != StatusNoShow {
+		t.Errorf("expected no_show to parse, got %q, %v", status, ok)
+	}
+	if _, ok := StringToStatus("archived"); ok {
+		t.Error("expected unknown status to be rejected")
+	}
+}
+
+func TestTransitionError(t *testing.T) {
+	err := transitionError(StatusCompleted, StatusConfirmed)
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected error to wrap ErrInvalidTransition, got %v", err)
+	}
+}
+
+func TestHeldEntryStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		to          Status
+		wantStatus  string
+		wantBooking bool
+	}{
+		{name: "converted hold fulfills the entry", to: StatusPending, wantStatus: "fulfilled", wantBooking: true},
+		{name: "released or unclaimed hold expires the entry", to: StatusExpired, wantStatus: "expired", wantBooking: true},
+		{name: "hold cancelled by the business puts the entry back in line", to: StatusCancelledByBusiness, wantStatus: "waiting"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, keepBooking := heldEntryStatus(tt.to)
+			if status != tt.wantStatus || keepBooking != tt.wantBooking {
+				t.Errorf("heldEntryStatus(%s) = %s, %v, want %s, %v", tt.to, status, keepBooking, tt.wantStatus, tt.wantBooking)
+			}
+		})
+	}
+}
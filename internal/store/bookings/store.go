@@ -4,35 +4,115 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"booking-service/pkg/money"
+
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const bookingColumns = `id, user_id, business_id, specialist_id, service_id, start_time, end_time, status, ` +
+	`source, guest_name, guest_phone, guest_email, series_id, session_id, hold_expires_at, assignment_strategy, ` +
+	`created_at, updated_at`
+
+type Source string
+
+const (
+	// SourceOnline bookings are made by registered customers themselves.
+	SourceOnline Source = "online"
+	// SourceManual bookings are added by the business, e.g. for walk-in or phone customers.
+	SourceManual Source = "manual"
+)
+
+// Guest describes a customer without a user account. Guest bookings are linked to the account
+// created later with the same email.
+type Guest struct {
+	Name  string  `json:"name"`
+	Phone *string `json:"phone,omitempty"`
+	Email *string `json:"email,omitempty"`
+}
+
 type Booking struct {
-	ID         string    `json:"id"`
-	UserID     string    `json:"user_id"`
-	BusinessID string    `json:"business_id"`
-	ServiceID  string    `json:"service_id"`
-	StartTime  time.Time `json:"start_time"`
-	EndTime    time.Time `json:"end_time"`
-	Status     string    `json:"status"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID           string    `json:"id"`
+	UserID       *string   `json:"user_id"`
+	BusinessID   string    `json:"business_id"`
+	SpecialistID *string   `json:"specialist_id,omitempty"`
+	ServiceID    string    `json:"service_id"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	Status       Status    `json:"status"`
+	Source       Source    `json:"source"`
+	Guest        *Guest    `json:"guest,omitempty"`
+	SeriesID     *string   `json:"series_id,omitempty"`
+	// SessionID is set on bookings of group classes: the class session they attend.
+	SessionID *string `json:"session_id,omitempty"`
+	// HoldExpiresAt is set on held bookings: the moment the hold is released unless claimed.
+	HoldExpiresAt *time.Time `json:"hold_expires_at,omitempty"`
+	// AssignmentStrategy is set on bookings made for any available specialist: the strategy that picked one.
+	AssignmentStrategy *string   `json:"assignment_strategy,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	// Items are the services performed, in order. They are only loaded for a single booking, not for lists.
+	Items      []BookingItem `json:"items,omitempty"`
+	TotalPrice *money.Money  `json:"total_price,omitempty"`
 }
 
 type CreateBookingRequest struct {
-	UserID     string    `json:"user_id"`
-	BusinessID string    `json:"business_id"`
-	ServiceID  string    `json:"service_id"`
+	// UserID is the customer, always the authenticated caller; it is never read from the request body.
+	UserID   string  `json:"-"`
+	Guest    *Guest  `json:"-"`
+	Source   Source  `json:"-"`
+	SeriesID *string `json:"-"`
+	// HoldExpiresAt creates the booking as a hold, released at the given time unless claimed.
+	HoldExpiresAt *time.Time `json:"-"`
+	// AssignmentStrategy records the strategy that assigned the specialist, if the customer named none.
+	AssignmentStrategy *string `json:"-"`
+	BusinessID         string  `json:"business_id"`
+	// SpecialistID may be left out to book any available specialist performing the services.
+	SpecialistID *string `json:"specialist_id,omitempty"`
+	ServiceID    string  `json:"service_id"`
+	// ServiceIDs books several services of the business back to back in one appointment, in the given order.
+	ServiceIDs []string `json:"service_ids,omitempty"`
+	// VariantIDs and AddOnIDs pick options of the booked services, at most one variant per service.
+	VariantIDs []string  `json:"variant_ids,omitempty"`
+	AddOnIDs   []string  `json:"add_on_ids,omitempty"`
 	StartTime  time.Time `json:"start_time"`
 	EndTime    time.Time `json:"end_time"`
+	// Items are the services to perform, with the duration and price of each. ServiceID is the first of them.
+	Items []BookingItem `json:"-"`
+}
+
+type RescheduleBookingRequest struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
 }
 
 type Store interface {
 	GetBooking(ctx context.Context, id string) (*Booking, error)
 	CreateBooking(ctx context.Context, req CreateBookingRequest) (*Booking, error)
+	TransitionBooking(ctx context.Context, id string, to Status, actorUserID string) (*Booking, error)
+	RescheduleBooking(ctx context.Context, id string, req RescheduleBookingRequest, actorUserID string) (*Booking, error)
+	ListBookings(ctx context.Context, req ListBookingsRequest) (*ListBookingsResponse, error)
+	ListBusinessBookings(ctx context.Context, req ListBusinessBookingsRequest) ([]*CalendarEntry, error)
+	ListBusyWindows(ctx context.Context, businessID string, specialistID *string, from, to time.Time) ([]TimeWindow, error)
+	ListSpecialistBusyWindows(ctx context.Context, specialistIDs []string, from, to time.Time) (map[string][]TimeWindow, error)
+	ListSpecialistLoads(ctx context.Context, businessID string, specialistIDs []string, from, to time.Time) (map[string]SpecialistLoad, error)
+	LinkGuestBookings(ctx context.Context, userID, email string) (int64, error)
+
+	CreateSeries(ctx context.Context, req CreateSeriesRequest) (*SeriesResult, error)
+	GetSeries(ctx context.Context, id string) (*Series, error)
+	ListSeriesBookings(ctx context.Context, seriesID string) ([]*Booking, error)
+	TransitionBookings(ctx context.Context, ids []string, to Status, actorUserID string) ([]*Booking, error)
+	RescheduleBookings(ctx context.Context, changes []BookingReschedule, actorUserID string) ([]*Booking, error)
+
+	ListExpiredHolds(ctx context.Context, now time.Time) ([]*Booking, error)
+
+	ListSessions(ctx context.Context, businessID string, from, to time.Time) ([]*ClassSession, error)
 }
 
 type PgStore struct {
@@ -49,26 +129,65 @@ func NewStore(readPool, writePool *pgxpool.Pool) *PgStore {
 	}
 }
 
-func (s *PgStore) GetBooking(ctx context.Context, id string) (*Booking, error) {
-	query := `
-		SELECT id, user_id, business_id, service_id, start_time, end_time, status, created_at, updated_at
-		FROM bookings
-		WHERE id = $1
-	`
+// queryRower is implemented by the pool as well as by a transaction.
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
 
-	var booking Booking
-	err := s.readPool.QueryRow(ctx, query, id).Scan(
+// scanBooking scans the bookingColumns of a row; extra destinations receive the columns selected after them.
+func scanBooking(row pgx.Row, extra ...interface{}) (*Booking, error) {
+	var (
+		booking                           Booking
+		guestName, guestPhone, guestEmail *string
+	)
+	dest := []interface{}{
 		&booking.ID,
 		&booking.UserID,
 		&booking.BusinessID,
+		&booking.SpecialistID,
 		&booking.ServiceID,
 		&booking.StartTime,
 		&booking.EndTime,
 		&booking.Status,
+		&booking.Source,
+		&guestName,
+		&guestPhone,
+		&guestEmail,
+		&booking.SeriesID,
+		&booking.SessionID,
+		&booking.HoldExpiresAt,
+		&booking.AssignmentStrategy,
 		&booking.CreatedAt,
 		&booking.UpdatedAt,
-	)
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if guestName != nil {
+		booking.Guest = &Guest{Name: *guestName, Phone: guestPhone, Email: guestEmail}
+	}
+
+	return &booking, nil
+}
+
+// qualifiedBookingColumns returns bookingColumns prefixed with a table alias, for queries joining other tables.
+func qualifiedBookingColumns(alias string) string {
+	columns := strings.Split(bookingColumns, ", ")
+	for i, column := range columns {
+		columns[i] = alias + "." + column
+	}
+	return strings.Join(columns, ", ")
+}
 
+func (s *PgStore) GetBooking(ctx context.Context, id string) (*Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings
+		WHERE id = $1
+	`
+
+	booking, err := scanBooking(s.readPool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -76,56 +195,545 @@ func (s *PgStore) GetBooking(ctx context.Context, id string) (*Booking, error) {
 		return nil, err
 	}
 
-	return &booking, nil
+	items, err := listItems(ctx, s.readPool, booking)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking items: %w", err)
+	}
+	booking.setItems(items)
+
+	return booking, nil
 }
 
 func (s *PgStore) CreateBooking(ctx context.Context, req CreateBookingRequest) (*Booking, error) {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	booking, err := s.insertBooking(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return booking, nil
+}
+
+// insertBooking creates a booking within tx, so series occurrences can share one transaction. Bookings of
+// group classes join their class session.
+func (s *PgStore) insertBooking(ctx context.Context, tx pgx.Tx, req CreateBookingRequest) (*Booking, error) {
 	query := `
 		INSERT INTO bookings (
-			id, user_id, business_id, service_id, start_time, end_time, status, created_at, updated_at
+			id, user_id, business_id, specialist_id, service_id, start_time, end_time, status,
+			source, guest_name, guest_phone, guest_email, series_id, session_id, hold_expires_at, assignment_strategy,
+			created_at, updated_at, blocked_start, blocked_end
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9
-		) RETURNING id, user_id, business_id, service_id, start_time, end_time, status, created_at, updated_at
-	`
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+		) RETURNING ` + bookingColumns
 
 	now := time.Now()
 	booking := &Booking{
-		ID:         uuid.New().String(),
-		UserID:     req.UserID,
-		BusinessID: req.BusinessID,
-		ServiceID:  req.ServiceID,
-		StartTime:  req.StartTime,
-		EndTime:    req.EndTime,
-		Status:     "pending", // Initial status
-		CreatedAt:  now,
-		UpdatedAt:  now,
-	}
-
-	err := s.writePool.QueryRow(ctx, query,
+		ID:                 uuid.New().String(),
+		BusinessID:         req.BusinessID,
+		SpecialistID:       req.SpecialistID,
+		ServiceID:          req.ServiceID,
+		StartTime:          req.StartTime,
+		EndTime:            req.EndTime,
+		Status:             StatusPending,
+		Source:             req.Source,
+		Guest:              req.Guest,
+		SeriesID:           req.SeriesID,
+		HoldExpiresAt:      req.HoldExpiresAt,
+		AssignmentStrategy: req.AssignmentStrategy,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if req.UserID != "" {
+		booking.UserID = &req.UserID
+	}
+	if booking.HoldExpiresAt != nil {
+		booking.Status = StatusHeld
+	}
+	if booking.Source == "" {
+		booking.Source = SourceOnline
+	}
+
+	blocked, err := blockedWindow(ctx, tx, req.ServiceID, lastServiceID(req.ServiceID, req.Items), req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	booking.SessionID, err = joinSession(ctx, tx, booking.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var guestName, guestPhone, guestEmail *string
+	if booking.Guest != nil {
+		guestName, guestPhone, guestEmail = &booking.Guest.Name, booking.Guest.Phone, booking.Guest.Email
+	}
+
+	booking, err = scanBooking(tx.QueryRow(ctx, query,
 		booking.ID,
 		booking.UserID,
 		booking.BusinessID,
+		booking.SpecialistID,
 		booking.ServiceID,
 		booking.StartTime,
 		booking.EndTime,
 		booking.Status,
+		booking.Source,
+		guestName,
+		guestPhone,
+		guestEmail,
+		booking.SeriesID,
+		booking.SessionID,
+		booking.HoldExpiresAt,
+		booking.AssignmentStrategy,
 		booking.CreatedAt,
 		booking.UpdatedAt,
-	).Scan(
-		&booking.ID,
-		&booking.UserID,
-		&booking.BusinessID,
-		&booking.ServiceID,
-		&booking.StartTime,
-		&booking.EndTime,
-		&booking.Status,
-		&booking.CreatedAt,
-		&booking.UpdatedAt,
-	)
+		blocked.StartTime,
+		blocked.EndTime,
+	))
+	if err != nil {
+		if isOverlapViolation(err) {
+			return nil, overlapError(ctx, s.writePool, req.BusinessID, req.SpecialistID, blocked)
+		}
+		return nil, err
+	}
+
+	items := append([]BookingItem(nil), req.Items...)
+	scheduleItems(items, booking.StartTime)
+	if err := insertItems(ctx, tx, booking.ID, items); err != nil {
+		return nil, err
+	}
+	booking.setItems(items)
+
+	return booking, nil
+}
+
+// TransitionBooking moves a booking to the given status and records who did it; an empty actorUserID
+// stands for the service itself. The current status is read
+// under a row lock, so concurrent transitions of the same booking are serialized and an illegal move is never persisted.
+func (s *PgStore) TransitionBooking(ctx context.Context, id string, to Status, actorUserID string) (*Booking, error) {
+	bookings, err := s.TransitionBookings(ctx, []string{id}, to, actorUserID)
+	if err != nil {
+		return nil, err
+	}
+	return bookings[0], nil
+}
+
+// TransitionBookings moves all the given bookings to the status in one transaction: either every booking
+// is moved or none is.
+func (s *PgStore) TransitionBookings(ctx context.Context, ids []string, to Status, actorUserID string) ([]*Booking, error) {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	bookings := make([]*Booking, 0, len(ids))
+	for _, id := range ids {
+		booking, err := transitionBooking(ctx, tx, id, to, actorUserID)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return bookings, nil
+}
+
+func transitionBooking(ctx context.Context, tx pgx.Tx, id string, to Status, actorUserID string) (*Booking, error) {
+	current, err := lockBooking(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !CanTransition(current.Status, to) {
+		return nil, transitionError(current.Status, to)
+	}
+
+	// A claimed hold becomes a regular booking
+	query := `
+		UPDATE bookings SET status = $1, hold_expires_at = NULL, updated_at = $2
+		WHERE id = $3
+		RETURNING ` + bookingColumns
+
+	booking, err := scanBooking(tx.QueryRow(ctx, query, to, time.Now(), id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update booking status: %w", err)
+	}
+
+	if current.Status == StatusHeld {
+		if err := releaseWaitlistEntry(ctx, tx, id, to); err != nil {
+			return nil, err
+		}
+	}
+
+	err = insertEvent(ctx, tx, Event{
+		BookingID:   id,
+		ActorUserID: actorUserID,
+		Action:      EventStatusChanged,
+		FromStatus:  current.Status,
+		ToStatus:    to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return booking, nil
+}
+
+// releaseWaitlistEntry settles the waitlist entry a held booking was offered to, if any, as the hold leaves
+// the held status.
+func releaseWaitlistEntry(ctx context.Context, tx pgx.Tx, bookingID string, to Status) error {
+	status, keepBooking := heldEntryStatus(to)
+
+	query := `
+		UPDATE waitlist_entries
+		SET status = $1, booking_id = CASE WHEN $2 THEN booking_id END, updated_at = $3
+		WHERE booking_id = $4 AND status = 'offered'
+	`
+
+	if _, err := tx.Exec(ctx, query, status, keepBooking, time.Now(), bookingID); err != nil {
+		return fmt.Errorf("failed to release waitlist entry: %w", err)
+	}
+
+	return nil
+}
+
+// heldEntryStatus is the status of the waitlist entry whose hold moves to status to, and whether the entry
+// keeps the booking. A claimed offer fulfills the entry and an offer withdrawn by the business puts it back
+// in line; otherwise the offer has expired, and a customer declining it refines the entry status afterwards.
+func heldEntryStatus(to Status) (status string, keepBooking bool) {
+	switch to {
+	case StatusPending:
+		return "fulfilled", true
+	case StatusCancelledByBusiness:
+		return "waiting", false
+	default:
+		return "expired", true
+	}
+}
+
+// BookingReschedule is the new time window of one booking.
+type BookingReschedule struct {
+	BookingID string
+	RescheduleBookingRequest
+}
+
+// RescheduleBooking moves a booking to a new time window. The new window goes through the same
+// overlap protection as a new booking.
+func (s *PgStore) RescheduleBooking(ctx context.Context, id string, req RescheduleBookingRequest, actorUserID string) (*Booking, error) {
+	bookings, err := s.RescheduleBookings(ctx, []BookingReschedule{{BookingID: id, RescheduleBookingRequest: req}}, actorUserID)
+	if err != nil {
+		return nil, err
+	}
+	return bookings[0], nil
+}
+
+// RescheduleBookings moves all the given bookings in one transaction: if any of them overlaps another booking,
+// none is moved. The bookings are returned in the order of changes.
+func (s *PgStore) RescheduleBookings(ctx context.Context, changes []BookingReschedule, actorUserID string) ([]*Booking, error) {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current := make([]*Booking, len(changes))
+	starts := make([]time.Time, len(changes))
+	for i, change := range changes {
+		if current[i], err = lockBooking(ctx, tx, change.BookingID); err != nil {
+			return nil, err
+		}
+		starts[i] = current[i].StartTime
+	}
+
+	bookings := make([]*Booking, len(changes))
+	for _, i := range rescheduleOrder(changes, starts) {
+		bookings[i], err = s.rescheduleBooking(ctx, tx, current[i], changes[i].RescheduleBookingRequest, actorUserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return bookings, nil
+}
+
+// rescheduleOrder returns the order to apply changes in, given the current start times of their bookings.
+// The overlap constraint is checked on every update, so bookings moving later are moved latest first and
+// bookings moving earlier earliest first: a shifted series then never overlaps an occurrence that has
+// not been moved yet.
+func rescheduleOrder(changes []BookingReschedule, starts []time.Time) []int {
+	order := make([]int, len(changes))
+	for i := range order {
+		order[i] = i
+	}
+
+	later := func(i int) bool { return changes[i].StartTime.After(starts[i]) }
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if later(i) != later(j) {
+			return !later(i)
+		}
+		if later(i) {
+			return starts[i].After(starts[j])
+		}
+		return starts[i].Before(starts[j])
+	})
 
+	return order
+}
+
+// rescheduleBooking moves the booking current, locked by the caller, to the window of req.
+func (s *PgStore) rescheduleBooking(ctx context.Context, tx pgx.Tx, current *Booking, req RescheduleBookingRequest, actorUserID string) (*Booking, error) {
+	id := current.ID
+	if !CanTransition(current.Status, StatusRescheduled) {
+		return nil, transitionError(current.Status, StatusRescheduled)
+	}
+
+	query := `
+		UPDATE bookings SET status = $1, start_time = $2, end_time = $3, blocked_start = $4, blocked_end = $5,
+			session_id = $6, updated_at = $7
+		WHERE id = $8
+		RETURNING ` + bookingColumns
+
+	items, err := listItems(ctx, tx, current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking items: %w", err)
+	}
+
+	blocked, err := blockedWindow(ctx, tx, current.ServiceID, lastServiceID(current.ServiceID, items), req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// A rescheduled class booking moves to the session at its new time
+	sessionID, err := joinSession(ctx, tx, id, CreateBookingRequest{
+		UserID:       derefString(current.UserID),
+		BusinessID:   current.BusinessID,
+		SpecialistID: current.SpecialistID,
+		ServiceID:    current.ServiceID,
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	booking, err := scanBooking(tx.QueryRow(ctx, query,
+		StatusRescheduled, req.StartTime, req.EndTime, blocked.StartTime, blocked.EndTime, sessionID, time.Now(), id))
+	if err != nil {
+		if isOverlapViolation(err) {
+			// The transaction is aborted at this point, so the conflict is looked up outside of it
+			return nil, overlapError(ctx, s.writePool, current.BusinessID, current.SpecialistID, blocked)
+		}
+		return nil, fmt.Errorf("failed to reschedule booking: %w", err)
+	}
+	scheduleItems(items, booking.StartTime)
+	booking.setItems(items)
+
+	err = insertEvent(ctx, tx, Event{
+		BookingID:   id,
+		ActorUserID: actorUserID,
+		Action:      EventRescheduled,
+		FromStatus:  current.Status,
+		ToStatus:    StatusRescheduled,
+		FromWindow:  &TimeWindow{StartTime: current.StartTime, EndTime: current.EndTime},
+		ToWindow:    &TimeWindow{StartTime: req.StartTime, EndTime: req.EndTime},
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return booking, nil
 }
+
+// lockBooking reads a booking and locks its row until the end of the transaction.
+func lockBooking(ctx context.Context, tx pgx.Tx, id string) (*Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	booking, err := scanBooking(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, fmt.Errorf("failed to lock booking: %w", err)
+	}
+
+	return booking, nil
+}
+
+// ListBusyWindows returns the time windows taken by active bookings of the business (and specialist)
+// that overlap [from, to). A window covers the booking together with the buffers of its service.
+func (s *PgStore) ListBusyWindows(ctx context.Context, businessID string, specialistID *string, from, to time.Time) ([]TimeWindow, error) {
+	query := `
+		SELECT blocked_start, blocked_end
+		FROM bookings
+		WHERE business_id = $1
+			AND specialist_id IS NOT DISTINCT FROM $2
+			AND ` + activeBookingCondition + `
+			AND tstzrange(blocked_start, blocked_end) && tstzrange($3, $4)
+		ORDER BY blocked_start
+	`
+
+	rows, err := s.readPool.Query(ctx, query, businessID, specialistID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []TimeWindow
+	for rows.Next() {
+		var window TimeWindow
+		if err := rows.Scan(&window.StartTime, &window.EndTime); err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+
+	return windows, rows.Err()
+}
+
+// ListSpecialistBusyWindows returns the busy windows of every given specialist that overlap [from, to), like
+// ListBusyWindows, by specialist id.
+func (s *PgStore) ListSpecialistBusyWindows(ctx context.Context, specialistIDs []string, from, to time.Time) (map[string][]TimeWindow, error) {
+	query := `
+		SELECT specialist_id, blocked_start, blocked_end
+		FROM bookings
+		WHERE specialist_id = ANY($1)
+			AND ` + activeBookingCondition + `
+			AND tstzrange(blocked_start, blocked_end) && tstzrange($2, $3)
+		ORDER BY specialist_id, blocked_start
+	`
+
+	rows, err := s.readPool.Query(ctx, query, specialistIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := make(map[string][]TimeWindow)
+	for rows.Next() {
+		var (
+			specialistID string
+			window       TimeWindow
+		)
+		if err := rows.Scan(&specialistID, &window.StartTime, &window.EndTime); err != nil {
+			return nil, err
+		}
+		windows[specialistID] = append(windows[specialistID], window)
+	}
+
+	return windows, rows.Err()
+}
+
+// SpecialistLoad is how busy a specialist is, for the assignment of bookings made for any specialist.
+type SpecialistLoad struct {
+	// Bookings counts the active bookings of the specialist starting in the requested period.
+	Bookings int
+	// LastAssignedAt is when the specialist was last assigned a booking automatically, nil if never.
+	LastAssignedAt *time.Time
+}
+
+// ListSpecialistLoads returns the load of every given specialist of the business, with bookings counted
+// within [from, to).
+func (s *PgStore) ListSpecialistLoads(ctx context.Context, businessID string, specialistIDs []string,
+	from, to time.Time) (map[string]SpecialistLoad, error) {
+	query := `
+		SELECT sp.id,
+			COUNT(b.id) FILTER (WHERE b.start_time >= $3 AND b.start_time < $4 AND b.` + activeBookingCondition + `),
+			MAX(b.created_at) FILTER (WHERE b.assignment_strategy IS NOT NULL)
+		FROM unnest($2::uuid[]) AS sp(id)
+		LEFT JOIN bookings b ON b.business_id = $1 AND b.specialist_id = sp.id
+		GROUP BY sp.id
+	`
+
+	rows, err := s.readPool.Query(ctx, query, businessID, specialistIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loads := make(map[string]SpecialistLoad, len(specialistIDs))
+	for rows.Next() {
+		var (
+			specialistID string
+			load         SpecialistLoad
+		)
+		if err := rows.Scan(&specialistID, &load.Bookings, &load.LastAssignedAt); err != nil {
+			return nil, err
+		}
+		loads[specialistID] = load
+	}
+
+	return loads, rows.Err()
+}
+
+// LinkGuestBookings attaches the guest bookings made with the given email to the user account.
+func (s *PgStore) LinkGuestBookings(ctx context.Context, userID, email string) (int64, error) {
+	query := `
+		UPDATE bookings SET user_id = $1, updated_at = $2
+		WHERE user_id IS NULL AND lower(guest_email) = lower($3)
+	`
+
+	result, err := s.writePool.Exec(ctx, query, userID, time.Now(), email)
+	if err != nil {
+		return 0, fmt.Errorf("failed to link guest bookings: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// ListExpiredHolds returns the held bookings whose hold ended before now.
+func (s *PgStore) ListExpiredHolds(ctx context.Context, now time.Time) ([]*Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings
+		WHERE status = $1 AND hold_expires_at < $2
+		ORDER BY hold_expires_at
+	`
+
+	rows, err := s.writePool.Query(ctx, query, StatusHeld, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []*Booking
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, booking)
+	}
+
+	return holds, rows.Err()
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
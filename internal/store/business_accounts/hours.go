@@ -0,0 +1,44 @@
+package business_accounts
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	workingHoursTable   = "business_working_hours"
+	hoursOverridesTable = "business_hours_overrides"
+
+	DefaultTimezone = "UTC"
+)
+
+var (
+	OverrideNotFoundError = errors.New("working hours override not found")
+)
+
+// TimeInterval is an opening interval within a day. Times are wall-clock "HH:MM" in the business timezone.
+type TimeInterval struct {
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// WorkingInterval is a regular weekly opening interval. Weekday follows time.Weekday, 0 is Sunday.
+type WorkingInterval struct {
+	Weekday time.Weekday `json:"weekday"`
+	TimeInterval
+}
+
+// HoursOverride replaces the weekly hours on a specific date: a closed day (holiday), a short day
+// or an extra opening on a day that is normally closed.
+type HoursOverride struct {
+	Date      string         `json:"date"`
+	Closed    bool           `json:"closed"`
+	Intervals []TimeInterval `json:"intervals,omitempty"`
+}
+
+type WorkingHours struct {
+	BusinessAccountID string            `json:"business_account_id"`
+	Timezone          string            `json:"timezone"`
+	Weekly            []WorkingInterval `json:"weekly"`
+	Overrides         []HoursOverride   `json:"overrides"`
+}
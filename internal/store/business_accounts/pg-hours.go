@@ -0,0 +1,192 @@
+package business_accounts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+func (s *PgStore) GetTimezone(ctx context.Context, businessAccountID string) (string, error) {
+	query := fmt.Sprintf(`SELECT timezone FROM %s WHERE id = $1`, businessAccountsTable)
+
+	var timezone string
+	if err := s.readPool.QueryRow(ctx, query, businessAccountID).Scan(&timezone); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", NotFoundError
+		}
+		return "", fmt.Errorf("failed to get business timezone: %w", err)
+	}
+
+	return timezone, nil
+}
+
+func (s *PgStore) GetWorkingHours(ctx context.Context, businessAccountID string) (*WorkingHours, error) {
+	hours := &WorkingHours{
+		BusinessAccountID: businessAccountID,
+		Weekly:            []WorkingInterval{},
+		Overrides:         []HoursOverride{},
+	}
+
+	timezone, err := s.GetTimezone(ctx, businessAccountID)
+	if err != nil {
+		return nil, err
+	}
+	hours.Timezone = timezone
+
+	weeklyQuery := fmt.Sprintf(`
+		SELECT weekday, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')
+		FROM %s
+		WHERE business_account_id = $1
+		ORDER BY weekday, start_time
+	`, workingHoursTable)
+
+	rows, err := s.readPool.Query(ctx, weeklyQuery, businessAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working hours: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var interval WorkingInterval
+		if err := rows.Scan(&interval.Weekday, &interval.StartTime, &interval.EndTime); err != nil {
+			return nil, fmt.Errorf("failed to scan working hours: %w", err)
+		}
+		hours.Weekly = append(hours.Weekly, interval)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read working hours: %w", err)
+	}
+
+	overridesQuery := fmt.Sprintf(`
+		SELECT to_char(date, 'YYYY-MM-DD'), is_closed, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')
+		FROM %s
+		WHERE business_account_id = $1
+		ORDER BY date, start_time NULLS FIRST
+	`, hoursOverridesTable)
+
+	overrideRows, err := s.readPool.Query(ctx, overridesQuery, businessAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working hours overrides: %w", err)
+	}
+	defer overrideRows.Close()
+
+	for overrideRows.Next() {
+		var (
+			date       string
+			closed     bool
+			start, end *string
+		)
+		if err := overrideRows.Scan(&date, &closed, &start, &end); err != nil {
+			return nil, fmt.Errorf("failed to scan working hours override: %w", err)
+		}
+
+		// Rows of the same date are adjacent thanks to the ORDER BY
+		last := len(hours.Overrides) - 1
+		if last < 0 || hours.Overrides[last].Date != date {
+			hours.Overrides = append(hours.Overrides, HoursOverride{Date: date, Closed: closed})
+			last++
+		}
+		if !closed && start != nil && end != nil {
+			hours.Overrides[last].Intervals = append(hours.Overrides[last].Intervals, TimeInterval{StartTime: *start, EndTime: *end})
+		}
+	}
+
+	return hours, overrideRows.Err()
+}
+
+// SetWeeklyHours replaces the timezone and the whole weekly schedule of the business account.
+func (s *PgStore) SetWeeklyHours(ctx context.Context, businessAccountID, timezone string, weekly []WorkingInterval) error {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tzQuery := fmt.Sprintf(`UPDATE %s SET timezone = $1, updated_at = now() WHERE id = $2`, businessAccountsTable)
+	result, err := tx.Exec(ctx, tzQuery, timezone, businessAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to update business timezone: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return NotFoundError
+	}
+
+	delQuery := fmt.Sprintf(`DELETE FROM %s WHERE business_account_id = $1`, workingHoursTable)
+	if _, err := tx.Exec(ctx, delQuery, businessAccountID); err != nil {
+		return fmt.Errorf("failed to delete working hours: %w", err)
+	}
+
+	insQuery := fmt.Sprintf(`
+		INSERT INTO %s (id, business_account_id, weekday, start_time, end_time)
+		VALUES ($1, $2, $3, $4::time, $5::time)
+	`, workingHoursTable)
+	for _, interval := range weekly {
+		_, err := tx.Exec(ctx, insQuery, uuid.New().String(), businessAccountID, int(interval.Weekday),
+			interval.StartTime, interval.EndTime)
+		if err != nil {
+			return fmt.Errorf("failed to insert working hours: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SetHoursOverride creates or replaces the override of a single date.
+func (s *PgStore) SetHoursOverride(ctx context.Context, businessAccountID string, override HoursOverride) error {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	delQuery := fmt.Sprintf(`DELETE FROM %s WHERE business_account_id = $1 AND date = $2::date`, hoursOverridesTable)
+	if _, err := tx.Exec(ctx, delQuery, businessAccountID, override.Date); err != nil {
+		return fmt.Errorf("failed to delete working hours override: %w", err)
+	}
+
+	insQuery := fmt.Sprintf(`
+		INSERT INTO %s (id, business_account_id, date, is_closed, start_time, end_time)
+		VALUES ($1, $2, $3::date, $4, $5::time, $6::time)
+	`, hoursOverridesTable)
+
+	if override.Closed {
+		_, err := tx.Exec(ctx, insQuery, uuid.New().String(), businessAccountID, override.Date, true, nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to insert working hours override: %w", err)
+		}
+	}
+	for _, interval := range override.Intervals {
+		_, err := tx.Exec(ctx, insQuery, uuid.New().String(), businessAccountID, override.Date, false,
+			interval.StartTime, interval.EndTime)
+		if err != nil {
+			return fmt.Errorf("failed to insert working hours override: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PgStore) DeleteHoursOverride(ctx context.Context, businessAccountID, date string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE business_account_id = $1 AND date = $2::date`, hoursOverridesTable)
+
+	result, err := s.writePool.Exec(ctx, query, businessAccountID, date)
+	if err != nil {
+		return fmt.Errorf("failed to delete working hours override: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return OverrideNotFoundError
+	}
+
+	return nil
+}
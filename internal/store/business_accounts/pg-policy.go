@@ -0,0 +1,40 @@
+package business_accounts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+func (s *PgStore) GetBookingPolicy(ctx context.Context, businessAccountID string) (*BookingPolicy, error) {
+	query := fmt.Sprintf(`SELECT cancellation_cutoff_minutes, assignment_strategy FROM %s WHERE id = $1`, businessAccountsTable)
+
+	var policy BookingPolicy
+	err := s.readPool.QueryRow(ctx, query, businessAccountID).Scan(&policy.CancellationCutoffMinutes, &policy.AssignmentStrategy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, NotFoundError
+		}
+		return nil, fmt.Errorf("failed to get booking policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+func (s *PgStore) UpdateBookingPolicy(ctx context.Context, businessAccountID string, policy BookingPolicy) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET cancellation_cutoff_minutes = $1, assignment_strategy = $2, updated_at = now()
+		WHERE id = $3
+	`, businessAccountsTable)
+
+	result, err := s.writePool.Exec(ctx, query, policy.CancellationCutoffMinutes, policy.AssignmentStrategy, businessAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to update booking policy: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return NotFoundError
+	}
+
+	return nil
+}
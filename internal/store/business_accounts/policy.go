@@ -0,0 +1,17 @@
+package business_accounts
+
+import "time"
+
+const DefaultCancellationCutoffMinutes = 24 * 60
+
+// BookingPolicy holds the rules a business applies to bookings made by its customers.
+type BookingPolicy struct {
+	// CancellationCutoffMinutes is how long before the start customers may still cancel or reschedule.
+	CancellationCutoffMinutes int `json:"cancellation_cutoff_minutes"`
+	// AssignmentStrategy picks the specialist of bookings made for any available specialist.
+	AssignmentStrategy string `json:"assignment_strategy"`
+}
+
+func (p BookingPolicy) CancellationCutoff() time.Duration {
+	return time.Duration(p.CancellationCutoffMinutes) * time.Minute
+}
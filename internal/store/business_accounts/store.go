@@ -19,4 +19,13 @@ type Store interface {
 	DeleteBusinessAccount(ctx context.Context, businessAccountID string) error
 	UserOwnsBusinessAccount(ctx context.Context, businessAccountID, userID string) (bool, error)
 	GetBusinessAccount(ctx context.Context, businessAccountID string) (*BusinessAccount, error)
+
+	GetTimezone(ctx context.Context, businessAccountID string) (string, error)
+	GetWorkingHours(ctx context.Context, businessAccountID string) (*WorkingHours, error)
+	SetWeeklyHours(ctx context.Context, businessAccountID, timezone string, weekly []WorkingInterval) error
+	SetHoursOverride(ctx context.Context, businessAccountID string, override HoursOverride) error
+	DeleteHoursOverride(ctx context.Context, businessAccountID, date string) error
+
+	GetBookingPolicy(ctx context.Context, businessAccountID string) (*BookingPolicy, error)
+	UpdateBookingPolicy(ctx context.Context, businessAccountID string, policy BookingPolicy) error
 }
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking-service/pkg/money"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// optionColumns are selected from service_options joined with services as s, options are priced in the
+// currency of their service.
+const optionColumns = `o.id, o.service_id, o.kind, o.name, o.duration_minutes, o.price_minor, s.currency,
+			o.created_at, o.updated_at`
+
+type OptionKind string
+
+const (
+	// OptionVariant replaces the duration and price of its service, e.g. a haircut for long hair.
+	OptionVariant OptionKind = "variant"
+	// OptionAddOn is booked on top of its service and adds its duration and price, e.g. a beard trim.
+	OptionAddOn OptionKind = "add_on"
+)
+
+var ErrOptionNotFound = errors.New("service option not found")
+
+// Option is a variant or an add-on of a service.
+type Option struct {
+	ID              string      `json:"id"`
+	ServiceID       string      `json:"service_id"`
+	Kind            OptionKind  `json:"kind"`
+	Name            string      `json:"name"`
+	DurationMinutes int         `json:"duration_minutes"`
+	Price           money.Money `json:"price"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+// CreateOptionRequest and UpdateOptionRequest price options in the currency of their service; the currency
+// of the price may be left out.
+type CreateOptionRequest struct {
+	Name            string      `json:"name"`
+	DurationMinutes int         `json:"duration_minutes"`
+	Price           money.Money `json:"price"`
+}
+
+type UpdateOptionRequest struct {
+	Name            *string      `json:"name,omitempty"`
+	DurationMinutes *int         `json:"duration_minutes,omitempty"`
+	Price           *money.Money `json:"price,omitempty"`
+}
+
+func scanOption(row pgx.Row) (*Option, error) {
+	var option Option
+	err := row.Scan(
+		&option.ID,
+		&option.ServiceID,
+		&option.Kind,
+		&option.Name,
+		&option.DurationMinutes,
+		&option.Price.Amount,
+		&option.Price.Currency,
+		&option.CreatedAt,
+		&option.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &option, nil
+}
+
+func (s *PgStore) CreateOption(ctx context.Context, serviceID string, kind OptionKind, req CreateOptionRequest) (*Option, error) {
+	query := `
+		WITH o AS (
+			INSERT INTO service_options (id, service_id, kind, name, duration_minutes, price_minor, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING *
+		)
+		SELECT ` + optionColumns + `
+		FROM o
+		JOIN services s ON s.id = o.service_id
+	`
+
+	now := time.Now()
+	return scanOption(s.writePool.QueryRow(ctx, query,
+		uuid.New().String(), serviceID, kind, req.Name, req.DurationMinutes, req.Price.Amount, now, now))
+}
+
+func (s *PgStore) GetOption(ctx context.Context, id string) (*Option, error) {
+	query := `
+		SELECT ` + optionColumns + `
+		FROM service_options o
+		JOIN services s ON s.id = o.service_id
+		WHERE o.id = $1
+	`
+
+	option, err := scanOption(s.readPool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return option, nil
+}
+
+// ListOptions returns the variants and add-ons of the services, ordered by service, kind and name.
+func (s *PgStore) ListOptions(ctx context.Context, serviceIDs []string) ([]*Option, error) {
+	query := `
+		SELECT ` + optionColumns + `
+		FROM service_options o
+		JOIN services s ON s.id = o.service_id
+		WHERE o.service_id = ANY($1)
+		ORDER BY o.service_id, o.kind DESC, o.name, o.id
+	`
+
+	rows, err := s.readPool.Query(ctx, query, serviceIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	options := []*Option{}
+	for rows.Next() {
+		option, err := scanOption(rows)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, option)
+	}
+
+	return options, rows.Err()
+}
+
+func (s *PgStore) UpdateOption(ctx context.Context, id string, req UpdateOptionRequest) (*Option, error) {
+	query := `
+		WITH o AS (
+			UPDATE service_options SET
+				name = COALESCE($1, name),
+				duration_minutes = COALESCE($2, duration_minutes),
+				price_minor = COALESCE($3, price_minor),
+				updated_at = $4
+			WHERE id = $5
+			RETURNING *
+		)
+		SELECT ` + optionColumns + `
+		FROM o
+		JOIN services s ON s.id = o.service_id
+	`
+
+	var price *int64
+	if req.Price != nil {
+		price = &req.Price.Amount
+	}
+
+	option, err := scanOption(s.writePool.QueryRow(ctx, query, req.Name, req.DurationMinutes, price, time.Now(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOptionNotFound
+		}
+		return nil, fmt.Errorf("failed to update service option: %w", err)
+	}
+
+	return option, nil
+}
+
+func (s *PgStore) DeleteOption(ctx context.Context, id string) error {
+	result, err := s.writePool.Exec(ctx, `DELETE FROM service_options WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrOptionNotFound
+	}
+
+	return nil
+}
+
+// Selection is a service as booked: with the chosen variant, if any, and add-ons.
+type Selection struct {
+	Service *Service
+	Variant *Option
+	AddOns  []*Option
+}
+
+// Performed returns the service with the duration and price of the selection, for validating the booking.
+func (s Selection) Performed() *Service {
+	performed := *s.Service
+	if s.Variant != nil {
+		performed.DurationMinutes = s.Variant.DurationMinutes
+		performed.Price = s.Variant.Price
+	}
+	for _, addOn := range s.AddOns {
+		performed.DurationMinutes += addOn.DurationMinutes
+		performed.Price.Amount += addOn.Price.Amount
+	}
+	return &performed
+}
+
+// OptionError is a variant or add-on that cannot be booked with the chosen services.
+type OptionError struct {
+	// Field is the request field naming the option.
+	Field   string
+	Message string
+}
+
+func (e *OptionError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Select applies the chosen variants and add-ons to the booked services. options are the options of the
+// booked services; every chosen one must be among them, and a service takes at most one variant.
+func Select(booked []*Service, options []*Option, variantIDs, addOnIDs []string) ([]Selection, error) {
+	selections := make([]Selection, len(booked))
+	index := make(map[string]int, len(booked))
+	for i, service := range booked {
+		selections[i].Service = service
+		index[service.ID] = i
+	}
+
+	byID := make(map[string]*Option, len(options))
+	for _, option := range options {
+		byID[option.ID] = option
+	}
+
+	for _, id := range variantIDs {
+		option, ok := byID[id]
+		if !ok || option.Kind != OptionVariant {
+			return nil, &OptionError{Field: "variant_ids", Message: fmt.Sprintf("%s is not a variant of the booked services", id)}
+		}
+		selection := &selections[index[option.ServiceID]]
+		if selection.Variant != nil {
+			return nil, &OptionError{Field: "variant_ids", Message: fmt.Sprintf("only one variant of service %s can be booked", option.ServiceID)}
+		}
+		selection.Variant = option
+	}
+
+	for _, id := range addOnIDs {
+		option, ok := byID[id]
+		if !ok || option.Kind != OptionAddOn {
+			return nil, &OptionError{Field: "add_on_ids", Message: fmt.Sprintf("%s is not an add-on of the booked services", id)}
+		}
+		selection := &selections[index[option.ServiceID]]
+		for _, addOn := range selection.AddOns {
+			if addOn.ID == id {
+				return nil, &OptionError{Field: "add_on_ids", Message: fmt.Sprintf("add-on %s is chosen twice", id)}
+			}
+		}
+		selection.AddOns = append(selection.AddOns, option)
+	}
+
+	return selections, nil
+}
@@ -6,22 +6,91 @@ import (
 	"errors"
 	"time"
 
+	"booking-service/pkg/money"
+
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const serviceColumns = `id, business_account_id, name, description, duration_minutes,
+			price_minor, currency, category, is_active, buffer_before_minutes, buffer_after_minutes,
+			min_lead_minutes, max_horizon_days, capacity, created_at, updated_at`
+
 type Service struct {
-	ID                string    `json:"id"`
-	BusinessAccountID string    `json:"business_account_id"`
-	Name              string    `json:"name"`
-	Description       *string   `json:"description,omitempty"`
-	DurationMinutes   int       `json:"duration_minutes"`
-	Price             float64   `json:"price"`
-	Currency          string    `json:"currency"`
-	Category          *string   `json:"category,omitempty"`
-	IsActive          bool      `json:"is_active"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                string      `json:"id"`
+	BusinessAccountID string      `json:"business_account_id"`
+	Name              string      `json:"name"`
+	Description       *string     `json:"description,omitempty"`
+	DurationMinutes   int         `json:"duration_minutes"`
+	Price             money.Money `json:"price"`
+	Category          *string     `json:"category,omitempty"`
+	IsActive          bool        `json:"is_active"`
+	BookingRules
+	// Capacity is the number of customers attending one appointment. Services with a capacity above one
+	// are group classes: their bookings share a class session.
+	Capacity  int       `json:"capacity"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Variants and AddOns are only loaded for a single service, not for lists.
+	Variants []*Option `json:"variants,omitempty"`
+	AddOns   []*Option `json:"add_ons,omitempty"`
+}
+
+// BookingRules restrict when a service can be booked.
+type BookingRules struct {
+	// BufferBeforeMinutes and BufferAfterMinutes keep the time around an appointment free,
+	// e.g. for preparation and cleanup. Buffers of neighbouring bookings must not overlap.
+	BufferBeforeMinutes int `json:"buffer_before_minutes"`
+	BufferAfterMinutes  int `json:"buffer_after_minutes"`
+	// MinLeadMinutes is how long in advance the service must be booked at least.
+	MinLeadMinutes int `json:"min_lead_minutes"`
+	// MaxHorizonDays is how far into the future the service can be booked, 0 means no limit.
+	MaxHorizonDays int `json:"max_horizon_days"`
+}
+
+// IsGroupClass reports whether several customers can book the same appointment.
+func (s *Service) IsGroupClass() bool {
+	return s.Capacity > 1
+}
+
+func (r BookingRules) BufferBefore() time.Duration {
+	return time.Duration(r.BufferBeforeMinutes) * time.Minute
+}
+
+func (r BookingRules) BufferAfter() time.Duration {
+	return time.Duration(r.BufferAfterMinutes) * time.Minute
+}
+
+// EarliestStart is the first moment the service can start when booked at now.
+func (r BookingRules) EarliestStart(now time.Time) time.Time {
+	return now.Add(time.Duration(r.MinLeadMinutes) * time.Minute)
+}
+
+// LatestStart is the last moment the service can start when booked at now; the zero time means no limit.
+func (r BookingRules) LatestStart(now time.Time) time.Time {
+	if r.MaxHorizonDays == 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, r.MaxHorizonDays)
+}
+
+// Combine returns the service performed by booking the given services back to back in one appointment: their
+// durations and prices add up, the buffers surround the whole appointment and the strictest lead time and
+// horizon apply. The services must share one currency.
+func Combine(services []*Service) *Service {
+	combined := *services[0]
+	for _, service := range services[1:] {
+		combined.DurationMinutes += service.DurationMinutes
+		combined.Price.Amount += service.Price.Amount
+		combined.IsActive = combined.IsActive && service.IsActive
+		combined.MinLeadMinutes = max(combined.MinLeadMinutes, service.MinLeadMinutes)
+		if combined.MaxHorizonDays == 0 || (service.MaxHorizonDays != 0 && service.MaxHorizonDays < combined.MaxHorizonDays) {
+			combined.MaxHorizonDays = service.MaxHorizonDays
+		}
+		combined.BufferAfterMinutes = service.BufferAfterMinutes
+	}
+	return &combined
 }
 
 type CreateServiceRequest struct {
@@ -29,19 +98,33 @@ type CreateServiceRequest struct {
 	Name              string  `json:"name"`
 	Description       *string `json:"description,omitempty"`
 	DurationMinutes   int     `json:"duration_minutes"`
-	Price             float64 `json:"price"`
-	Currency          string  `json:"currency"`
-	Category          *string `json:"category,omitempty"`
+	// Price defaults to the USD currency when none is given.
+	Price    money.Money `json:"price"`
+	Category *string     `json:"category,omitempty"`
+	BookingRules
+	// Capacity defaults to 1, a one-to-one appointment.
+	Capacity int `json:"capacity,omitempty"`
 }
 
 type UpdateServiceRequest struct {
-	Name            *string  `json:"name,omitempty"`
-	Description     *string  `json:"description,omitempty"`
-	DurationMinutes *int     `json:"duration_minutes,omitempty"`
-	Price           *float64 `json:"price,omitempty"`
-	Currency        *string  `json:"currency,omitempty"`
-	Category        *string  `json:"category,omitempty"`
-	IsActive        *bool    `json:"is_active,omitempty"`
+	Name                *string      `json:"name,omitempty"`
+	Description         *string      `json:"description,omitempty"`
+	DurationMinutes     *int         `json:"duration_minutes,omitempty"`
+	Price               *PriceUpdate `json:"price,omitempty"`
+	Category            *string      `json:"category,omitempty"`
+	IsActive            *bool        `json:"is_active,omitempty"`
+	BufferBeforeMinutes *int         `json:"buffer_before_minutes,omitempty"`
+	BufferAfterMinutes  *int         `json:"buffer_after_minutes,omitempty"`
+	MinLeadMinutes      *int         `json:"min_lead_minutes,omitempty"`
+	MaxHorizonDays      *int         `json:"max_horizon_days,omitempty"`
+	Capacity            *int         `json:"capacity,omitempty"`
+}
+
+// PriceUpdate is a new price of a service. The amount is required, so a price is never reset by mistake;
+// the currency of the service is kept when none is given.
+type PriceUpdate struct {
+	Amount   *int64         `json:"amount"`
+	Currency money.Currency `json:"currency,omitempty"`
 }
 
 type ListServicesRequest struct {
@@ -64,6 +147,12 @@ type Store interface {
 	DeleteService(ctx context.Context, id string) error
 	ListServices(ctx context.Context, req ListServicesRequest) (*ListServicesResponse, error)
 	GetServicesByBusinessAccount(ctx context.Context, businessAccountID string) ([]*Service, error)
+
+	CreateOption(ctx context.Context, serviceID string, kind OptionKind, req CreateOptionRequest) (*Option, error)
+	GetOption(ctx context.Context, id string) (*Option, error)
+	ListOptions(ctx context.Context, serviceIDs []string) ([]*Option, error)
+	UpdateOption(ctx context.Context, id string, req UpdateOptionRequest) (*Option, error)
+	DeleteOption(ctx context.Context, id string) error
 }
 
 type PgStore struct {
@@ -80,16 +169,42 @@ func NewStore(readPool, writePool *pgxpool.Pool) *PgStore {
 	}
 }
 
+func scanService(row pgx.Row) (*Service, error) {
+	var service Service
+	err := row.Scan(
+		&service.ID,
+		&service.BusinessAccountID,
+		&service.Name,
+		&service.Description,
+		&service.DurationMinutes,
+		&service.Price.Amount,
+		&service.Price.Currency,
+		&service.Category,
+		&service.IsActive,
+		&service.BufferBeforeMinutes,
+		&service.BufferAfterMinutes,
+		&service.MinLeadMinutes,
+		&service.MaxHorizonDays,
+		&service.Capacity,
+		&service.CreatedAt,
+		&service.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service, nil
+}
+
 func (s *PgStore) CreateService(ctx context.Context, req CreateServiceRequest) (*Service, error) {
 	query := `
 		INSERT INTO services (
-			id, business_account_id, name, description, duration_minutes, 
-			price, currency, category, is_active, created_at, updated_at
+			id, business_account_id, name, description, duration_minutes,
+			price_minor, currency, category, is_active, buffer_before_minutes, buffer_after_minutes,
+			min_lead_minutes, max_horizon_days, capacity, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
-		) RETURNING id, business_account_id, name, description, duration_minutes, 
-			price, currency, category, is_active, created_at, updated_at
-	`
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+		) RETURNING ` + serviceColumns
 
 	now := time.Now()
 	service := &Service{
@@ -99,73 +214,49 @@ func (s *PgStore) CreateService(ctx context.Context, req CreateServiceRequest) (
 		Description:       req.Description,
 		DurationMinutes:   req.DurationMinutes,
 		Price:             req.Price,
-		Currency:          req.Currency,
 		Category:          req.Category,
 		IsActive:          true,
+		BookingRules:      req.BookingRules,
+		Capacity:          req.Capacity,
 		CreatedAt:         now,
 		UpdatedAt:         now,
 	}
 
-	if service.Currency == "" {
-		service.Currency = "USD"
+	if service.Price.Currency == "" {
+		service.Price.Currency = "USD"
+	}
+	if service.Capacity == 0 {
+		service.Capacity = 1
 	}
 
-	err := s.writePool.QueryRow(ctx, query,
+	return scanService(s.writePool.QueryRow(ctx, query,
 		service.ID,
 		service.BusinessAccountID,
 		service.Name,
 		service.Description,
 		service.DurationMinutes,
-		service.Price,
-		service.Currency,
+		service.Price.Amount,
+		service.Price.Currency,
 		service.Category,
 		service.IsActive,
+		service.BufferBeforeMinutes,
+		service.BufferAfterMinutes,
+		service.MinLeadMinutes,
+		service.MaxHorizonDays,
+		service.Capacity,
 		service.CreatedAt,
 		service.UpdatedAt,
-	).Scan(
-		&service.ID,
-		&service.BusinessAccountID,
-		&service.Name,
-		&service.Description,
-		&service.DurationMinutes,
-		&service.Price,
-		&service.Currency,
-		&service.Category,
-		&service.IsActive,
-		&service.CreatedAt,
-		&service.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return service, nil
+	))
 }
 
 func (s *PgStore) GetService(ctx context.Context, id string) (*Service, error) {
 	query := `
-		SELECT id, business_account_id, name, description, duration_minutes, 
-			price, currency, category, is_active, created_at, updated_at
+		SELECT ` + serviceColumns + `
 		FROM services
 		WHERE id = $1
 	`
 
-	var service Service
-	err := s.readPool.QueryRow(ctx, query, id).Scan(
-		&service.ID,
-		&service.BusinessAccountID,
-		&service.Name,
-		&service.Description,
-		&service.DurationMinutes,
-		&service.Price,
-		&service.Currency,
-		&service.Category,
-		&service.IsActive,
-		&service.CreatedAt,
-		&service.UpdatedAt,
-	)
-
+	service, err := scanService(s.readPool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -173,9 +264,13 @@ func (s *PgStore) GetService(ctx context.Context, id string) (*Service, error) {
 		return nil, err
 	}
 
-	return &service, nil
+	return service, nil
 }
 
+// ErrCurrencyLocked is returned when the currency of a service with variants or add-ons is changed: their
+// prices are in the currency of the service and would silently change their value.
+var ErrCurrencyLocked = errors.New("the currency of a service with variants or add-ons cannot be changed")
+
 func (s *PgStore) UpdateService(ctx context.Context, id string, req UpdateServiceRequest) (*Service, error) {
 	// First get the current service to merge with updates
 	currentService, err := s.GetService(ctx, id)
@@ -188,50 +283,59 @@ func (s *PgStore) UpdateService(ctx context.Context, id string, req UpdateServic
 
 	// Build dynamic update query
 	query := `
-		UPDATE services SET 
+		UPDATE services SET
 			name = COALESCE($1, name),
 			description = COALESCE($2, description),
 			duration_minutes = COALESCE($3, duration_minutes),
-			price = COALESCE($4, price),
+			price_minor = COALESCE($4, price_minor),
 			currency = COALESCE($5, currency),
 			category = COALESCE($6, category),
 			is_active = COALESCE($7, is_active),
-			updated_at = $8
-		WHERE id = $9
-		RETURNING id, business_account_id, name, description, duration_minutes, 
-			price, currency, category, is_active, created_at, updated_at
-	`
+			buffer_before_minutes = COALESCE($8, buffer_before_minutes),
+			buffer_after_minutes = COALESCE($9, buffer_after_minutes),
+			min_lead_minutes = COALESCE($10, min_lead_minutes),
+			max_horizon_days = COALESCE($11, max_horizon_days),
+			capacity = COALESCE($12, capacity),
+			updated_at = $13
+		WHERE id = $14
+			AND ($5 IS NULL OR $5 = currency
+				OR NOT EXISTS (SELECT 1 FROM service_options WHERE service_id = $14))
+		RETURNING ` + serviceColumns
+
+	var price *int64
+	var currency *money.Currency
+	if req.Price != nil {
+		price = req.Price.Amount
+		if req.Price.Currency != "" {
+			currency = &req.Price.Currency
+		}
+	}
 
 	now := time.Now()
-	err = s.writePool.QueryRow(ctx, query,
+	service, err := scanService(s.writePool.QueryRow(ctx, query,
 		req.Name,
 		req.Description,
 		req.DurationMinutes,
-		req.Price,
-		req.Currency,
+		price,
+		currency,
 		req.Category,
 		req.IsActive,
+		req.BufferBeforeMinutes,
+		req.BufferAfterMinutes,
+		req.MinLeadMinutes,
+		req.MaxHorizonDays,
+		req.Capacity,
 		now,
 		id,
-	).Scan(
-		&currentService.ID,
-		&currentService.BusinessAccountID,
-		&currentService.Name,
-		&currentService.Description,
-		&currentService.DurationMinutes,
-		&currentService.Price,
-		&currentService.Currency,
-		&currentService.Category,
-		&currentService.IsActive,
-		&currentService.CreatedAt,
-		&currentService.UpdatedAt,
-	)
-
+	))
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCurrencyLocked
+		}
 		return nil, err
 	}
 
-	return currentService, nil
+	return service, nil
 }
 
 func (s *PgStore) DeleteService(ctx context.Context, id string) error {
@@ -283,9 +387,8 @@ func (s *PgStore) ListServices(ctx context.Context, req ListServicesRequest) (*L
 
 	// Get services with pagination
 	servicesQuery := `
-		SELECT id, business_account_id, name, description, duration_minutes, 
-			price, currency, category, is_active, created_at, updated_at
-		FROM services 
+		SELECT ` + serviceColumns + `
+		FROM services
 		` + whereClause + `
 		ORDER BY created_at DESC
 		LIMIT $` + string(rune(argIndex+'0')) + ` OFFSET $` + string(rune(argIndex+1+'0'))
@@ -300,24 +403,11 @@ func (s *PgStore) ListServices(ctx context.Context, req ListServicesRequest) (*L
 
 	var services []*Service
 	for rows.Next() {
-		var service Service
-		err := rows.Scan(
-			&service.ID,
-			&service.BusinessAccountID,
-			&service.Name,
-			&service.Description,
-			&service.DurationMinutes,
-			&service.Price,
-			&service.Currency,
-			&service.Category,
-			&service.IsActive,
-			&service.CreatedAt,
-			&service.UpdatedAt,
-		)
+		service, err := scanService(rows)
 		if err != nil {
 			return nil, err
 		}
-		services = append(services, &service)
+		services = append(services, service)
 	}
 
 	return &ListServicesResponse{
@@ -328,8 +418,7 @@ func (s *PgStore) ListServices(ctx context.Context, req ListServicesRequest) (*L
 
 func (s *PgStore) GetServicesByBusinessAccount(ctx context.Context, businessAccountID string) ([]*Service, error) {
 	query := `
-		SELECT id, business_account_id, name, description, duration_minutes, 
-			price, currency, category, is_active, created_at, updated_at
+		SELECT ` + serviceColumns + `
 		FROM services
 		WHERE business_account_id = $1 AND is_active = true
 		ORDER BY name ASC
@@ -343,24 +432,11 @@ func (s *PgStore) GetServicesByBusinessAccount(ctx context.Context, businessAcco
 
 	var services []*Service
 	for rows.Next() {
-		var service Service
-		err := rows.Scan(
-			&service.ID,
-			&service.BusinessAccountID,
-			&service.Name,
-			&service.Description,
-			&service.DurationMinutes,
-			&service.Price,
-			&service.Currency,
-			&service.Category,
-			&service.IsActive,
-			&service.CreatedAt,
-			&service.UpdatedAt,
-		)
+		service, err := scanService(rows)
 		if err != nil {
 			return nil, err
 		}
-		services = append(services, &service)
+		services = append(services, service)
 	}
 
 	return services, nil
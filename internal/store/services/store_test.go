@@ -1,8 +1,11 @@
 package services
 
 import (
+	"errors"
 	"testing"
 	"time"
+
+	"booking-service/pkg/money"
 )
 
 func TestCreateServiceRequest_Validation(t *testing.T) {
@@ -17,8 +20,7 @@ func TestCreateServiceRequest_Validation(t *testing.T) {
 				BusinessAccountID: "123",
 				Name:              "Test Service",
 				DurationMinutes:   60,
-				Price:             50.0,
-				Currency:          "USD",
+				Price:             money.New(5000, "USD"),
 			},
 			wantErr: false,
 		},
@@ -27,7 +29,7 @@ func TestCreateServiceRequest_Validation(t *testing.T) {
 			req: CreateServiceRequest{
 				Name:            "Test Service",
 				DurationMinutes: 60,
-				Price:           50.0,
+				Price:           money.New(5000, "USD"),
 			},
 			wantErr: true,
 		},
@@ -36,7 +38,7 @@ func TestCreateServiceRequest_Validation(t *testing.T) {
 			req: CreateServiceRequest{
 				BusinessAccountID: "123",
 				DurationMinutes:   60,
-				Price:             50.0,
+				Price:             money.New(5000, "USD"),
 			},
 			wantErr: true,
 		},
@@ -46,7 +48,7 @@ func TestCreateServiceRequest_Validation(t *testing.T) {
 				BusinessAccountID: "123",
 				Name:              "Test Service",
 				DurationMinutes:   0,
-				Price:             50.0,
+				Price:             money.New(5000, "USD"),
 			},
 			wantErr: true,
 		},
@@ -56,7 +58,7 @@ func TestCreateServiceRequest_Validation(t *testing.T) {
 				BusinessAccountID: "123",
 				Name:              "Test Service",
 				DurationMinutes:   60,
-				Price:             -10.0,
+				Price:             money.New(-1000, "USD"),
 			},
 			wantErr: true,
 		},
@@ -75,7 +77,7 @@ func TestCreateServiceRequest_Validation(t *testing.T) {
 			if tt.req.DurationMinutes <= 0 && !tt.wantErr {
 				t.Errorf("CreateServiceRequest validation failed: duration_minutes must be positive")
 			}
-			if tt.req.Price < 0 && !tt.wantErr {
+			if tt.req.Price.Amount < 0 && !tt.wantErr {
 				t.Errorf("CreateServiceRequest validation failed: price cannot be negative")
 			}
 		})
@@ -89,8 +91,7 @@ func TestService_JSONTags(t *testing.T) {
 		Name:              "Test Service",
 		Description:       nil,
 		DurationMinutes:   60,
-		Price:             50.0,
-		Currency:          "USD",
+		Price:             money.New(5000, "USD"),
 		Category:          nil,
 		IsActive:          true,
 		CreatedAt:         time.Now(),
@@ -121,3 +122,95 @@ func TestListServicesRequest_Defaults(t *testing.T) {
 		t.Errorf("Expected default offset to be 0, got %d", req.Offset)
 	}
 }
+
+func TestBookingRules_Window(t *testing.T) {
+	now := time.Date(2030, time.March, 4, 12, 0, 0, 0, time.UTC)
+
+	rules := BookingRules{MinLeadMinutes: 120, MaxHorizonDays: 60}
+	if got, want := rules.EarliestStart(now), now.Add(2*time.Hour); !got.Equal(want) {
+		t.Errorf("EarliestStart() = %s, want %s", got, want)
+	}
+	if got, want := rules.LatestStart(now), now.AddDate(0, 0, 60); !got.Equal(want) {
+		t.Errorf("LatestStart() = %s, want %s", got, want)
+	}
+
+	if got := (BookingRules{}).LatestStart(now); !got.IsZero() {
+		t.Errorf("expected no horizon without max_horizon_days, got %s", got)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	haircut := &Service{ID: "haircut", DurationMinutes: 30, Price: money.New(2500, "USD"), IsActive: true,
+		BookingRules: BookingRules{BufferBeforeMinutes: 5, BufferAfterMinutes: 10, MinLeadMinutes: 60}}
+	colour := &Service{ID: "colour", DurationMinutes: 90, Price: money.New(8000, "USD"), IsActive: true,
+		BookingRules: BookingRules{BufferBeforeMinutes: 15, BufferAfterMinutes: 20, MinLeadMinutes: 120, MaxHorizonDays: 30}}
+
+	got := Combine([]*Service{haircut, colour})
+
+	if got.ID != "haircut" {
+		t.Errorf("ID = %s, want the first service", got.ID)
+	}
+	if got.DurationMinutes != 120 || got.Price != money.New(10500, "USD") {
+		t.Errorf("duration, price = %d, %v, want 120, 105.00 USD", got.DurationMinutes, got.Price)
+	}
+	if got.BufferBeforeMinutes != 5 || got.BufferAfterMinutes != 20 {
+		t.Errorf("buffers = %d, %d, want 5, 20", got.BufferBeforeMinutes, got.BufferAfterMinutes)
+	}
+	if got.MinLeadMinutes != 120 || got.MaxHorizonDays != 30 {
+		t.Errorf("lead, horizon = %d, %d, want 120, 30", got.MinLeadMinutes, got.MaxHorizonDays)
+	}
+	if haircut.DurationMinutes != 30 {
+		t.Errorf("Combine() modified its input")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	haircut := &Service{ID: "haircut", DurationMinutes: 30, Price: money.New(2500, "USD")}
+	shave := &Service{ID: "shave", DurationMinutes: 20, Price: money.New(1500, "USD")}
+	options := []*Option{
+		{ID: "long", ServiceID: "haircut", Kind: OptionVariant, DurationMinutes: 45, Price: money.New(3500, "USD")},
+		{ID: "short", ServiceID: "haircut", Kind: OptionVariant, DurationMinutes: 20, Price: money.New(2000, "USD")},
+		{ID: "wash", ServiceID: "haircut", Kind: OptionAddOn, DurationMinutes: 10, Price: money.New(500, "USD")},
+		{ID: "towel", ServiceID: "shave", Kind: OptionAddOn, DurationMinutes: 0, Price: money.New(300, "USD")},
+	}
+
+	tests := []struct {
+		name      string
+		variants  []string
+		addOns    []string
+		wantField string
+		want      []int
+	}{
+		{name: "no options", want: []int{30, 20}},
+		{name: "variant and add-ons", variants: []string{"long"}, addOns: []string{"wash", "towel"}, want: []int{55, 20}},
+		{name: "two variants of a service", variants: []string{"long", "short"}, wantField: "variant_ids"},
+		{name: "add-on as variant", variants: []string{"wash"}, wantField: "variant_ids"},
+		{name: "unknown add-on", addOns: []string{"massage"}, wantField: "add_on_ids"},
+		{name: "add-on chosen twice", addOns: []string{"wash", "wash"}, wantField: "add_on_ids"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Select([]*Service{haircut, shave}, options, tt.variants, tt.addOns)
+			if tt.wantField != "" {
+				var optionErr *OptionError
+				if !errors.As(err, &optionErr) || optionErr.Field != tt.wantField {
+					t.Fatalf("Select() error = %v, want an error on %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			for i, selection := range got {
+				if d := selection.Performed().DurationMinutes; d != tt.want[i] {
+					t.Errorf("duration of %s = %d, want %d", selection.Service.ID, d, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if haircut.DurationMinutes != 30 {
+		t.Errorf("Performed() modified the service")
+	}
+}
@@ -0,0 +1,222 @@
+package sessions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const sessionColumns = `id, user_id, created_at, expires_at, revoked_at`
+
+var (
+	// ErrTokenNotFound is returned for refresh tokens that were never issued.
+	ErrTokenNotFound = errors.New("refresh token not found")
+	// ErrSessionEnded is returned for refresh tokens of sessions that expired or were revoked.
+	ErrSessionEnded = errors.New("session has ended")
+	// ErrTokenReused is returned when a refresh token is presented again after it was rotated. The token may
+	// have been stolen, so its session is revoked.
+	ErrTokenReused = errors.New("refresh token was already used")
+)
+
+// Session is a login of a user on one device. It lasts as long as its refresh tokens keep being rotated.
+type Session struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether access tokens of the session are still accepted.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// Store keeps sessions with the hashes of their refresh tokens; the tokens themselves are never stored.
+type Store interface {
+	CreateSession(ctx context.Context, userID, tokenHash string, expiresAt time.Time) (*Session, error)
+	GetSession(ctx context.Context, id string) (*Session, error)
+	RotateRefreshToken(ctx context.Context, tokenHash, newTokenHash string, expiresAt, now time.Time) (*Session, error)
+	RevokeSession(ctx context.Context, tokenHash string, now time.Time) error
+	RevokeUserSessions(ctx context.Context, userID string, now time.Time) error
+}
+
+type PgStore struct {
+	readPool  *pgxpool.Pool
+	writePool *pgxpool.Pool
+}
+
+// type check
+var _ Store = NewStore(nil, nil)
+
+func NewStore(readPool, writePool *pgxpool.Pool) *PgStore {
+	return &PgStore{
+		readPool:  readPool,
+		writePool: writePool,
+	}
+}
+
+// CreateSession starts a session of the user with its first refresh token.
+func (s *PgStore) CreateSession(ctx context.Context, userID, tokenHash string, expiresAt time.Time) (*Session, error) {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := fmt.Sprintf(`
+		INSERT INTO sessions (id, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING %s
+	`, sessionColumns)
+	session, err := scanSession(tx.QueryRow(ctx, query, uuid.New().String(), userID, expiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	if err := insertToken(ctx, tx, session.ID, tokenHash, expiresAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit session: %w", err)
+	}
+	return session, nil
+}
+
+// GetSession reads from the primary: a revocation must take effect at once, not when a replica catches up.
+func (s *PgStore) GetSession(ctx context.Context, id string) (*Session, error) {
+	query := fmt.Sprintf(`SELECT %s FROM sessions WHERE id = $1`, sessionColumns)
+
+	session, err := scanSession(s.writePool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return session, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one, extending its session until expiresAt.
+// A token rotated before revokes its session and returns ErrTokenReused.
+func (s *PgStore) RotateRefreshToken(ctx context.Context, tokenHash, newTokenHash string, expiresAt, now time.Time) (*Session, error) {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the session serializes concurrent rotations of its tokens
+	query := `
+		SELECT t.used_at, t.expires_at, s.id, s.user_id, s.created_at, s.expires_at, s.revoked_at
+		FROM refresh_tokens t
+		JOIN sessions s ON s.id = t.session_id
+		WHERE t.token_hash = $1
+		FOR UPDATE OF s
+	`
+
+	var usedAt *time.Time
+	var tokenExpiresAt time.Time
+	var session Session
+	err = tx.QueryRow(ctx, query, tokenHash).Scan(&usedAt, &tokenExpiresAt,
+		&session.ID, &session.UserID, &session.CreatedAt, &session.ExpiresAt, &session.RevokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if err := checkRotation(&session, tokenExpiresAt, usedAt, now); err != nil {
+		if !errors.Is(err, ErrTokenReused) {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE sessions SET revoked_at = $2 WHERE id = $1`, session.ID, now); err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit session revocation: %w", err)
+		}
+		return nil, ErrTokenReused
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET used_at = $2 WHERE token_hash = $1`, tokenHash, now); err != nil {
+		return nil, fmt.Errorf("failed to mark refresh token as used: %w", err)
+	}
+	if err := insertToken(ctx, tx, session.ID, newTokenHash, expiresAt); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE sessions SET expires_at = $2 WHERE id = $1`, session.ID, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to extend session: %w", err)
+	}
+	session.ExpiresAt = expiresAt
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit refresh token rotation: %w", err)
+	}
+	return &session, nil
+}
+
+// RevokeSession ends the session of the refresh token. Revoking an ended session is not an error.
+func (s *PgStore) RevokeSession(ctx context.Context, tokenHash string, now time.Time) error {
+	query := `
+		UPDATE sessions s SET revoked_at = COALESCE(s.revoked_at, $2)
+		FROM refresh_tokens t
+		WHERE t.token_hash = $1 AND s.id = t.session_id
+	`
+
+	result, err := s.writePool.Exec(ctx, query, tokenHash, now)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions ends all sessions of the user, logging them out on every device.
+func (s *PgStore) RevokeUserSessions(ctx context.Context, userID string, now time.Time) error {
+	query := `UPDATE sessions SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`
+
+	if _, err := s.writePool.Exec(ctx, query, userID, now); err != nil {
+		return fmt.Errorf("failed to revoke sessions of user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// checkRotation tells whether a refresh token of the session may be rotated: it returns ErrSessionEnded for
+// ended sessions and expired tokens, and ErrTokenReused for tokens rotated before.
+func checkRotation(session *Session, tokenExpiresAt time.Time, usedAt *time.Time, now time.Time) error {
+	if !session.Active(now) || !now.Before(tokenExpiresAt) {
+		return ErrSessionEnded
+	}
+	if usedAt != nil {
+		return ErrTokenReused
+	}
+	return nil
+}
+
+func insertToken(ctx context.Context, tx pgx.Tx, sessionID, tokenHash string, expiresAt time.Time) error {
+	query := `INSERT INTO refresh_tokens (token_hash, session_id, expires_at) VALUES ($1, $2, $3)`
+
+	if _, err := tx.Exec(ctx, query, tokenHash, sessionID, expiresAt); err != nil {
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return nil
+}
+
+func scanSession(row pgx.Row) (*Session, error) {
+	var session Session
+	if err := row.Scan(&session.ID, &session.UserID, &session.CreatedAt, &session.ExpiresAt, &session.RevokedAt); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -0,0 +1,62 @@
+package sessions
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSession_Active(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	revokedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		session Session
+		want    bool
+	}{
+		{name: "active", session: Session{ExpiresAt: now.Add(time.Hour)}, want: true},
+		{name: "expired", session: Session{ExpiresAt: now}},
+		{name: "revoked", session: Session{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.Active(now); got != tt.want {
+				t.Errorf("Active() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRotation(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	usedAt := now.Add(-time.Minute)
+	revokedAt := now.Add(-time.Minute)
+	active := &Session{ExpiresAt: now.Add(time.Hour)}
+
+	tests := []struct {
+		name           string
+		session        *Session
+		tokenExpiresAt time.Time
+		usedAt         *time.Time
+		want           error
+	}{
+		{name: "unused token", session: active, tokenExpiresAt: now.Add(time.Hour)},
+		{name: "used token", session: active, tokenExpiresAt: now.Add(time.Hour), usedAt: &usedAt, want: ErrTokenReused},
+		{name: "expired token", session: active, tokenExpiresAt: now, want: ErrSessionEnded},
+		{name: "expired session", session: &Session{ExpiresAt: now}, tokenExpiresAt: now.Add(time.Hour), want: ErrSessionEnded},
+		{name: "revoked session", session: &Session{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt},
+			tokenExpiresAt: now.Add(time.Hour), want: ErrSessionEnded},
+		{name: "used token of a revoked session", session: &Session{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt},
+			tokenExpiresAt: now.Add(time.Hour), usedAt: &usedAt, want: ErrSessionEnded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkRotation(tt.session, tt.tokenExpiresAt, tt.usedAt, now); !errors.Is(err, tt.want) {
+				t.Errorf("checkRotation() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
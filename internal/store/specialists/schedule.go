@@ -0,0 +1,206 @@
+package specialists
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking-service/internal/store/business_accounts"
+
+	"github.com/google/uuid"
+)
+
+var ErrTimeOffNotFound = errors.New("time off not found")
+
+// Schedule is when a specialist works within the working hours of their business.
+type Schedule struct {
+	SpecialistID string `json:"specialist_id"`
+	// Weekly are the shifts of the specialist. Without any, the specialist works whenever the business is open.
+	Weekly []business_accounts.WorkingInterval `json:"weekly"`
+	// Breaks recur every week, e.g. a lunch break.
+	Breaks []business_accounts.WorkingInterval `json:"breaks"`
+	// TimeOff lists the absences that have not ended yet.
+	TimeOff []*TimeOff `json:"time_off"`
+}
+
+// TimeOff is an absence of a specialist, e.g. a vacation or a sick day.
+type TimeOff struct {
+	ID           string    `json:"id"`
+	SpecialistID string    `json:"specialist_id"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	Reason       *string   `json:"reason,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SetScheduleRequest replaces the weekly shifts and breaks. Times are wall-clock "HH:MM" in the business timezone.
+type SetScheduleRequest struct {
+	Weekly []business_accounts.WorkingInterval `json:"weekly"`
+	Breaks []business_accounts.WorkingInterval `json:"breaks"`
+}
+
+type CreateTimeOffRequest struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Reason    *string   `json:"reason,omitempty"`
+}
+
+// GetSchedule returns the weekly schedule of the specialist with the time off ending after since.
+func (s *PgStore) GetSchedule(ctx context.Context, specialistID string, since time.Time) (*Schedule, error) {
+	schedules, err := s.ListSchedules(ctx, []string{specialistID}, since)
+	if err != nil {
+		return nil, err
+	}
+	return schedules[specialistID], nil
+}
+
+// ListSchedules returns the schedules of the specialists by id, like GetSchedule, in two queries for all of them.
+func (s *PgStore) ListSchedules(ctx context.Context, specialistIDs []string, since time.Time) (map[string]*Schedule, error) {
+	schedules := make(map[string]*Schedule, len(specialistIDs))
+	for _, id := range specialistIDs {
+		schedules[id] = &Schedule{
+			SpecialistID: id,
+			Weekly:       []business_accounts.WorkingInterval{},
+			Breaks:       []business_accounts.WorkingInterval{},
+			TimeOff:      []*TimeOff{},
+		}
+	}
+
+	rows, err := s.readPool.Query(ctx, `
+		SELECT specialist_id, weekday, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), is_break
+		FROM specialist_hours
+		WHERE specialist_id = ANY($1)
+		ORDER BY specialist_id, weekday, start_time
+	`, specialistIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get specialist hours: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			specialistID string
+			interval     business_accounts.WorkingInterval
+			isBreak      bool
+		)
+		if err := rows.Scan(&specialistID, &interval.Weekday, &interval.StartTime, &interval.EndTime, &isBreak); err != nil {
+			return nil, fmt.Errorf("failed to scan specialist hours: %w", err)
+		}
+		schedule := schedules[specialistID]
+		if isBreak {
+			schedule.Breaks = append(schedule.Breaks, interval)
+		} else {
+			schedule.Weekly = append(schedule.Weekly, interval)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read specialist hours: %w", err)
+	}
+
+	timeOffRows, err := s.readPool.Query(ctx, `
+		SELECT id, specialist_id, start_time, end_time, reason, created_at
+		FROM specialist_time_off
+		WHERE specialist_id = ANY($1) AND end_time > $2
+		ORDER BY specialist_id, start_time
+	`, specialistIDs, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time off: %w", err)
+	}
+	defer timeOffRows.Close()
+
+	for timeOffRows.Next() {
+		var timeOff TimeOff
+		err := timeOffRows.Scan(
+			&timeOff.ID,
+			&timeOff.SpecialistID,
+			&timeOff.StartTime,
+			&timeOff.EndTime,
+			&timeOff.Reason,
+			&timeOff.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan time off: %w", err)
+		}
+		schedule := schedules[timeOff.SpecialistID]
+		schedule.TimeOff = append(schedule.TimeOff, &timeOff)
+	}
+
+	return schedules, timeOffRows.Err()
+}
+
+// SetSchedule replaces the weekly shifts and breaks of the specialist.
+func (s *PgStore) SetSchedule(ctx context.Context, specialistID string, req SetScheduleRequest) error {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM specialist_hours WHERE specialist_id = $1`, specialistID); err != nil {
+		return fmt.Errorf("failed to delete specialist hours: %w", err)
+	}
+
+	insQuery := `
+		INSERT INTO specialist_hours (id, specialist_id, weekday, start_time, end_time, is_break)
+		VALUES ($1, $2, $3, $4::time, $5::time, $6)
+	`
+	insert := func(interval business_accounts.WorkingInterval, isBreak bool) error {
+		_, err := tx.Exec(ctx, insQuery, uuid.New().String(), specialistID, int(interval.Weekday),
+			interval.StartTime, interval.EndTime, isBreak)
+		if err != nil {
+			return fmt.Errorf("failed to insert specialist hours: %w", err)
+		}
+		return nil
+	}
+	for _, interval := range req.Weekly {
+		if err := insert(interval, false); err != nil {
+			return err
+		}
+	}
+	for _, interval := range req.Breaks {
+		if err := insert(interval, true); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PgStore) CreateTimeOff(ctx context.Context, specialistID string, req CreateTimeOffRequest) (*TimeOff, error) {
+	timeOff := &TimeOff{
+		ID:           uuid.New().String(),
+		SpecialistID: specialistID,
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+		Reason:       req.Reason,
+		CreatedAt:    time.Now(),
+	}
+
+	_, err := s.writePool.Exec(ctx, `
+		INSERT INTO specialist_time_off (id, specialist_id, start_time, end_time, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, timeOff.ID, timeOff.SpecialistID, timeOff.StartTime, timeOff.EndTime, timeOff.Reason, timeOff.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create time off: %w", err)
+	}
+
+	return timeOff, nil
+}
+
+func (s *PgStore) DeleteTimeOff(ctx context.Context, specialistID, timeOffID string) error {
+	result, err := s.writePool.Exec(ctx, `DELETE FROM specialist_time_off WHERE id = $1 AND specialist_id = $2`,
+		timeOffID, specialistID)
+	if err != nil {
+		return fmt.Errorf("failed to delete time off: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTimeOffNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,477 @@
+package specialists
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const specialistColumns = `sp.id, sp.business_account_id, sp.user_id, sp.linked_at, sp.name, sp.bio, sp.photo_url, ` +
+	`sp.area_type, sp.rating, sp.created_at, sp.updated_at`
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+)
+
+var (
+	ErrSpecialistNotFound = errors.New("specialist not found")
+	// ErrUserAlreadyLinked is returned when the user account already has a profile in the business.
+	ErrUserAlreadyLinked = errors.New("user account is already linked to a specialist of this business")
+	ErrUserNotFound      = errors.New("linked user account not found")
+)
+
+// Specialist is a staff member of a business account who performs its services.
+type Specialist struct {
+	ID                string `json:"id"`
+	BusinessAccountID string `json:"business_account_id"`
+	// UserID links the profile to the user account of the staff member, if they have one. The owner only
+	// invites the user; the link takes effect once the user accepts it, at LinkedAt.
+	UserID   *string    `json:"user_id,omitempty"`
+	LinkedAt *time.Time `json:"linked_at,omitempty"`
+	Name     string     `json:"name"`
+	Bio      *string    `json:"bio,omitempty"`
+	PhotoURL *string    `json:"photo_url,omitempty"`
+	AreaType string     `json:"area_type"`
+	// Rating goes from 0 to 5, nil until the specialist is rated.
+	Rating    *float64  `json:"rating,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// ServiceIDs are the services the specialist performs. They are only loaded for a single specialist.
+	ServiceIDs []string `json:"service_ids,omitempty"`
+}
+
+// LinkedTo reports whether the profile belongs to the user, who accepted the link.
+func (s *Specialist) LinkedTo(userID string) bool {
+	return s.UserID != nil && *s.UserID == userID && s.LinkedAt != nil
+}
+
+type CreateSpecialistRequest struct {
+	BusinessAccountID string   `json:"business_account_id"`
+	UserID            *string  `json:"user_id,omitempty"`
+	Name              string   `json:"name"`
+	Bio               *string  `json:"bio,omitempty"`
+	PhotoURL          *string  `json:"photo_url,omitempty"`
+	AreaType          string   `json:"area_type"`
+	Rating            *float64 `json:"rating,omitempty"`
+}
+
+type UpdateSpecialistRequest struct {
+	UserID   *string  `json:"user_id,omitempty"`
+	Name     *string  `json:"name,omitempty"`
+	Bio      *string  `json:"bio,omitempty"`
+	PhotoURL *string  `json:"photo_url,omitempty"`
+	AreaType *string  `json:"area_type,omitempty"`
+	Rating   *float64 `json:"rating,omitempty"`
+}
+
+// SearchQuery selects specialists; nil fields match all of them. The service filters are met by specialists
+// performing at least one active service that matches all of them.
+type SearchQuery struct {
+	BusinessAccountID *string
+	AreaType          *string
+	// City matches the location of the business account, case-insensitively.
+	City *string
+	// Categories matches services in any of the category slugs, usually a category with its sub-categories.
+	Categories []string
+	// MinPrice and MaxPrice bound service prices in minor units of Currency.
+	Currency *string
+	MinPrice *int64
+	MaxPrice *int64
+	Limit    int
+	Offset   int
+}
+
+// hasServiceFilters reports whether the query restricts the services of the specialists.
+func (q SearchQuery) hasServiceFilters() bool {
+	return q.Categories != nil || q.Currency != nil || q.MinPrice != nil || q.MaxPrice != nil
+}
+
+// SearchHit is a specialist found by a search.
+type SearchHit struct {
+	*Specialist
+	// MatchingServices is the number of active services of the specialist meeting the service filters.
+	MatchingServices int `json:"matching_services"`
+	// ShortestService is the shortest matching service, the one the next available slot is looked for.
+	ShortestService ShortestService `json:"-"`
+	// NextAvailable is the start of the first free slot of the specialist, only set when sorting by it.
+	NextAvailable *time.Time `json:"next_available,omitempty"`
+}
+
+// ShortestService holds the duration and booking rules of a service; the duration is 0 if there is none.
+type ShortestService struct {
+	DurationMinutes     int
+	BufferBeforeMinutes int
+	BufferAfterMinutes  int
+	MinLeadMinutes      int
+}
+
+type SearchResult struct {
+	Specialists []*SearchHit `json:"specialists"`
+	// Total counts all matches.
+	Total int64 `json:"total"`
+	// Ranked is how many of the most relevant matches were ranked by next available slot, only set when
+	// sorting by it; the pages cover these only.
+	Ranked int `json:"ranked,omitempty"`
+}
+
+type Store interface {
+	CreateSpecialist(ctx context.Context, req CreateSpecialistRequest) (*Specialist, error)
+	GetSpecialist(ctx context.Context, id string) (*Specialist, error)
+	UpdateSpecialist(ctx context.Context, id string, req UpdateSpecialistRequest) (*Specialist, error)
+	DeleteSpecialist(ctx context.Context, id string) error
+	AcceptLink(ctx context.Context, id, userID string) (*Specialist, error)
+	Unlink(ctx context.Context, id string) (*Specialist, error)
+	SearchSpecialists(ctx context.Context, query SearchQuery) (*SearchResult, error)
+
+	SetServices(ctx context.Context, specialistID string, serviceIDs []string) error
+	ListServiceIDs(ctx context.Context, specialistID string) ([]string, error)
+	CanPerform(ctx context.Context, specialistID, serviceID string) (bool, error)
+	ListQualified(ctx context.Context, businessAccountID string, serviceIDs []string) ([]*Specialist, error)
+
+	GetSchedule(ctx context.Context, specialistID string, since time.Time) (*Schedule, error)
+	ListSchedules(ctx context.Context, specialistIDs []string, since time.Time) (map[string]*Schedule, error)
+	SetSchedule(ctx context.Context, specialistID string, req SetScheduleRequest) error
+	CreateTimeOff(ctx context.Context, specialistID string, req CreateTimeOffRequest) (*TimeOff, error)
+	DeleteTimeOff(ctx context.Context, specialistID, timeOffID string) error
+}
+
+type PgStore struct {
+	readPool  *pgxpool.Pool
+	writePool *pgxpool.Pool
+}
+
+var _ Store = NewStore(nil, nil)
+
+func NewStore(readPool, writePool *pgxpool.Pool) *PgStore {
+	return &PgStore{
+		readPool:  readPool,
+		writePool: writePool,
+	}
+}
+
+func scanSpecialist(row pgx.Row) (*Specialist, error) {
+	var specialist Specialist
+	err := row.Scan(
+		&specialist.ID,
+		&specialist.BusinessAccountID,
+		&specialist.UserID,
+		&specialist.LinkedAt,
+		&specialist.Name,
+		&specialist.Bio,
+		&specialist.PhotoURL,
+		&specialist.AreaType,
+		&specialist.Rating,
+		&specialist.CreatedAt,
+		&specialist.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &specialist, nil
+}
+
+// linkError translates the violations of constraints on the linked user account.
+func linkError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == uniqueViolationCode:
+			return ErrUserAlreadyLinked
+		case pgErr.Code == foreignKeyViolationCode && pgErr.ConstraintName == "specialists_user_id_fkey":
+			return ErrUserNotFound
+		}
+	}
+	return err
+}
+
+func (s *PgStore) CreateSpecialist(ctx context.Context, req CreateSpecialistRequest) (*Specialist, error) {
+	query := `
+		INSERT INTO specialists AS sp (
+			id, business_account_id, user_id, name, bio, photo_url, area_type, rating, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		) RETURNING ` + specialistColumns
+
+	now := time.Now()
+	specialist, err := scanSpecialist(s.writePool.QueryRow(ctx, query,
+		uuid.New().String(), req.BusinessAccountID, req.UserID, req.Name, req.Bio, req.PhotoURL, req.AreaType, req.Rating, now, now))
+	if err != nil {
+		return nil, linkError(err)
+	}
+
+	return specialist, nil
+}
+
+func (s *PgStore) GetSpecialist(ctx context.Context, id string) (*Specialist, error) {
+	query := `
+		SELECT ` + specialistColumns + `
+		FROM specialists sp
+		WHERE sp.id = $1
+	`
+
+	specialist, err := scanSpecialist(s.readPool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return specialist, nil
+}
+
+func (s *PgStore) UpdateSpecialist(ctx context.Context, id string, req UpdateSpecialistRequest) (*Specialist, error) {
+	query := `
+		UPDATE specialists AS sp SET
+			user_id = COALESCE($1, user_id),
+			-- Another user has to accept the link again
+			linked_at = CASE WHEN $1 IS NULL OR $1 = user_id THEN linked_at END,
+			name = COALESCE($2, name),
+			bio = COALESCE($3, bio),
+			photo_url = COALESCE($4, photo_url),
+			area_type = COALESCE($5, area_type),
+			rating = COALESCE($6, rating),
+			updated_at = $7
+		WHERE sp.id = $8
+		RETURNING ` + specialistColumns
+
+	specialist, err := scanSpecialist(s.writePool.QueryRow(ctx, query,
+		req.UserID, req.Name, req.Bio, req.PhotoURL, req.AreaType, req.Rating, time.Now(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSpecialistNotFound
+		}
+		return nil, linkError(err)
+	}
+
+	return specialist, nil
+}
+
+func (s *PgStore) DeleteSpecialist(ctx context.Context, id string) error {
+	result, err := s.writePool.Exec(ctx, `DELETE FROM specialists WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrSpecialistNotFound
+	}
+
+	return nil
+}
+
+// AcceptLink links the profile to the invited user. Accepting twice keeps the first time.
+func (s *PgStore) AcceptLink(ctx context.Context, id, userID string) (*Specialist, error) {
+	query := `
+		UPDATE specialists AS sp SET
+			linked_at = COALESCE(linked_at, $3),
+			updated_at = $3
+		WHERE sp.id = $1 AND sp.user_id = $2
+		RETURNING ` + specialistColumns
+
+	specialist, err := scanSpecialist(s.writePool.QueryRow(ctx, query, id, userID, time.Now()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSpecialistNotFound
+		}
+		return nil, err
+	}
+
+	return specialist, nil
+}
+
+// Unlink removes the user account from the profile, or withdraws its invitation.
+func (s *PgStore) Unlink(ctx context.Context, id string) (*Specialist, error) {
+	query := `
+		UPDATE specialists AS sp SET
+			user_id = NULL,
+			linked_at = NULL,
+			updated_at = $2
+		WHERE sp.id = $1
+		RETURNING ` + specialistColumns
+
+	specialist, err := scanSpecialist(s.writePool.QueryRow(ctx, query, id, time.Now()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSpecialistNotFound
+		}
+		return nil, err
+	}
+
+	return specialist, nil
+}
+
+// searchMatches groups the specialists matching a search with their matching services.
+const searchMatches = `
+		FROM specialists sp
+		JOIN business_accounts b ON b.id = sp.business_account_id
+		LEFT JOIN specialist_services ss ON ss.specialist_id = sp.id
+		LEFT JOIN services svc ON svc.id = ss.service_id
+			AND svc.is_active
+			AND ($4::text[] IS NULL OR svc.category = ANY($4))
+			AND ($5::text IS NULL OR svc.currency = $5)
+			AND ($6::bigint IS NULL OR svc.price_minor >= $6)
+			AND ($7::bigint IS NULL OR svc.price_minor <= $7)
+		WHERE ($1::uuid IS NULL OR sp.business_account_id = $1)
+			AND ($2::text IS NULL OR sp.area_type = $2)
+			AND ($3::text IS NULL OR lower(b.location) = lower($3))
+		GROUP BY sp.id
+		HAVING NOT $8 OR COUNT(svc.id) > 0
+`
+
+// SearchSpecialists returns a page of the matching specialists, the most relevant first: those performing the
+// most matching services, then by name.
+func (s *PgStore) SearchSpecialists(ctx context.Context, q SearchQuery) (*SearchResult, error) {
+	args := []any{q.BusinessAccountID, q.AreaType, q.City, q.Categories, q.Currency, q.MinPrice, q.MaxPrice,
+		q.hasServiceFilters()}
+
+	result := &SearchResult{Specialists: []*SearchHit{}}
+	countQuery := `SELECT COUNT(*) FROM (SELECT sp.id ` + searchMatches + `) AS matches`
+	if err := s.readPool.QueryRow(ctx, countQuery, args...).Scan(&result.Total); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + specialistColumns + `, COUNT(svc.id),
+			COALESCE((array_agg(svc.duration_minutes ORDER BY svc.duration_minutes, svc.id) FILTER (WHERE svc.id IS NOT NULL))[1], 0),
+			COALESCE((array_agg(svc.buffer_before_minutes ORDER BY svc.duration_minutes, svc.id) FILTER (WHERE svc.id IS NOT NULL))[1], 0),
+			COALESCE((array_agg(svc.buffer_after_minutes ORDER BY svc.duration_minutes, svc.id) FILTER (WHERE svc.id IS NOT NULL))[1], 0),
+			COALESCE((array_agg(svc.min_lead_minutes ORDER BY svc.duration_minutes, svc.id) FILTER (WHERE svc.id IS NOT NULL))[1], 0)
+		` + searchMatches + `
+		ORDER BY COUNT(svc.id) DESC, sp.name, sp.id
+		LIMIT $9 OFFSET $10
+	`
+
+	rows, err := s.readPool.Query(ctx, query, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sp Specialist
+		hit := &SearchHit{Specialist: &sp}
+		err := rows.Scan(
+			&sp.ID,
+			&sp.BusinessAccountID,
+			&sp.UserID,
+			&sp.LinkedAt,
+			&sp.Name,
+			&sp.Bio,
+			&sp.PhotoURL,
+			&sp.AreaType,
+			&sp.Rating,
+			&sp.CreatedAt,
+			&sp.UpdatedAt,
+			&hit.MatchingServices,
+			&hit.ShortestService.DurationMinutes,
+			&hit.ShortestService.BufferBeforeMinutes,
+			&hit.ShortestService.BufferAfterMinutes,
+			&hit.ShortestService.MinLeadMinutes,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result.Specialists = append(result.Specialists, hit)
+	}
+
+	return result, rows.Err()
+}
+
+// SetServices replaces the services the specialist performs.
+func (s *PgStore) SetServices(ctx context.Context, specialistID string, serviceIDs []string) error {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM specialist_services WHERE specialist_id = $1`, specialistID); err != nil {
+		return fmt.Errorf("failed to clear services of specialist %s: %w", specialistID, err)
+	}
+
+	for _, serviceID := range serviceIDs {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO specialist_services (specialist_id, service_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, specialistID, serviceID)
+		if err != nil {
+			return fmt.Errorf("failed to assign service %s: %w", serviceID, err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *PgStore) ListServiceIDs(ctx context.Context, specialistID string) ([]string, error) {
+	rows, err := s.readPool.Query(ctx, `
+		SELECT service_id
+		FROM specialist_services
+		WHERE specialist_id = $1
+		ORDER BY service_id
+	`, specialistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	serviceIDs := []string{}
+	for rows.Next() {
+		var serviceID string
+		if err := rows.Scan(&serviceID); err != nil {
+			return nil, err
+		}
+		serviceIDs = append(serviceIDs, serviceID)
+	}
+
+	return serviceIDs, rows.Err()
+}
+
+// CanPerform reports whether the specialist is assigned to the service.
+func (s *PgStore) CanPerform(ctx context.Context, specialistID, serviceID string) (bool, error) {
+	var exists bool
+	err := s.readPool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM specialist_services WHERE specialist_id = $1 AND service_id = $2)
+	`, specialistID, serviceID).Scan(&exists)
+	return exists, err
+}
+
+// ListQualified returns the specialists of the business account performing all of the services, ordered by id.
+func (s *PgStore) ListQualified(ctx context.Context, businessAccountID string, serviceIDs []string) ([]*Specialist, error) {
+	query := `
+		SELECT ` + specialistColumns + `
+		FROM specialists sp
+		JOIN specialist_services ss ON ss.specialist_id = sp.id
+		WHERE sp.business_account_id = $1 AND ss.service_id = ANY($2::uuid[])
+		GROUP BY sp.id
+		HAVING COUNT(DISTINCT ss.service_id) = (SELECT COUNT(DISTINCT id) FROM unnest($2::uuid[]) AS id)
+		ORDER BY sp.id
+	`
+
+	rows, err := s.readPool.Query(ctx, query, businessAccountID, serviceIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var qualified []*Specialist
+	for rows.Next() {
+		specialist, err := scanSpecialist(rows)
+		if err != nil {
+			return nil, err
+		}
+		qualified = append(qualified, specialist)
+	}
+
+	return qualified, rows.Err()
+}
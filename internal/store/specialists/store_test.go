@@ -0,0 +1,34 @@
+package specialists
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpecialist_LinkedTo(t *testing.T) {
+	userID := "user-1"
+	linkedAt := time.Now()
+
+	tests := []struct {
+		name       string
+		specialist Specialist
+		want       bool
+	}{
+		{name: "linked", specialist: Specialist{UserID: &userID, LinkedAt: &linkedAt}, want: true},
+		{name: "invited", specialist: Specialist{UserID: &userID}},
+		{name: "not linked", specialist: Specialist{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.specialist.LinkedTo(userID); got != tt.want {
+				t.Errorf("LinkedTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	other := Specialist{UserID: &userID, LinkedAt: &linkedAt}
+	if other.LinkedTo("user-2") {
+		t.Errorf("LinkedTo() = true for another user")
+	}
+}
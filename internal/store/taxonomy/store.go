@@ -0,0 +1,331 @@
+package taxonomy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const categoryColumns = `c.id, c.slug, p.slug, c.level, c.names, c.created_at, c.updated_at`
+
+const uniqueViolationCode = "23505"
+
+// Levels of the taxonomy, from the root down.
+const (
+	LevelArea        = 1
+	LevelCategory    = 2
+	LevelSubcategory = 3
+)
+
+// DefaultLocale is the locale every category must have a name in, used when a requested one is missing.
+const DefaultLocale = "en"
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrParentNotFound   = errors.New("parent category not found")
+	ErrTooDeep          = errors.New("sub-categories cannot have children")
+	ErrSlugTaken        = errors.New("slug is already taken")
+	// ErrCategoryInUse is returned when deleting a category with children, specialists or services.
+	ErrCategoryInUse = errors.New("category is in use")
+)
+
+// Category is a node of the area → category → sub-category taxonomy. Specialists belong to an area,
+// services to a category or sub-category, both by slug.
+type Category struct {
+	ID         string  `json:"id"`
+	Slug       string  `json:"slug"`
+	ParentSlug *string `json:"parent_slug,omitempty"`
+	Level      int     `json:"level"`
+	// Names are the display names by locale, e.g. {"en": "Makeup", "de": "Make-up"}.
+	Names map[string]string `json:"names"`
+	// Name is the display name in the requested locale, set by the API.
+	Name      string      `json:"name,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Children  []*Category `json:"children,omitempty"`
+}
+
+// DisplayName returns the name in the locale, falling back to the default locale.
+func (c *Category) DisplayName(locale string) string {
+	if name, ok := c.Names[locale]; ok {
+		return name
+	}
+	return c.Names[DefaultLocale]
+}
+
+type CreateCategoryRequest struct {
+	Slug string `json:"slug"`
+	// ParentSlug places the category under another one; categories without a parent are areas.
+	ParentSlug *string           `json:"parent_slug,omitempty"`
+	Names      map[string]string `json:"names"`
+}
+
+// UpdateCategoryRequest renames a category. A new slug is applied to the specialists and services using it.
+type UpdateCategoryRequest struct {
+	Slug  *string           `json:"slug,omitempty"`
+	Names map[string]string `json:"names,omitempty"`
+}
+
+type Store interface {
+	ListCategories(ctx context.Context) ([]*Category, error)
+	GetCategory(ctx context.Context, slug string) (*Category, error)
+	CreateCategory(ctx context.Context, req CreateCategoryRequest) (*Category, error)
+	UpdateCategory(ctx context.Context, slug string, req UpdateCategoryRequest) (*Category, error)
+	DeleteCategory(ctx context.Context, slug string) error
+}
+
+type PgStore struct {
+	readPool  *pgxpool.Pool
+	writePool *pgxpool.Pool
+}
+
+var _ Store = NewStore(nil, nil)
+
+func NewStore(readPool, writePool *pgxpool.Pool) *PgStore {
+	return &PgStore{
+		readPool:  readPool,
+		writePool: writePool,
+	}
+}
+
+func scanCategory(row pgx.Row) (*Category, error) {
+	var category Category
+	err := row.Scan(
+		&category.ID,
+		&category.Slug,
+		&category.ParentSlug,
+		&category.Level,
+		&category.Names,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func slugError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return ErrSlugTaken
+	}
+	return err
+}
+
+// ListCategories returns all categories, ordered by level and slug.
+func (s *PgStore) ListCategories(ctx context.Context) ([]*Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories c
+		LEFT JOIN categories p ON p.id = c.parent_id
+		ORDER BY c.level, c.slug
+	`
+
+	rows, err := s.readPool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*Category
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+func (s *PgStore) GetCategory(ctx context.Context, slug string) (*Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories c
+		LEFT JOIN categories p ON p.id = c.parent_id
+		WHERE c.slug = $1
+	`
+
+	category, err := scanCategory(s.readPool.QueryRow(ctx, query, slug))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *PgStore) CreateCategory(ctx context.Context, req CreateCategoryRequest) (*Category, error) {
+	var parentID *string
+	level := LevelArea
+	if req.ParentSlug != nil {
+		parent, err := s.GetCategory(ctx, *req.ParentSlug)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, ErrParentNotFound
+		}
+		if parent.Level == LevelSubcategory {
+			return nil, ErrTooDeep
+		}
+		parentID, level = &parent.ID, parent.Level+1
+	}
+
+	query := `
+		WITH c AS (
+			INSERT INTO categories (id, parent_id, slug, level, names, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING *
+		)
+		SELECT ` + categoryColumns + `
+		FROM c
+		LEFT JOIN categories p ON p.id = c.parent_id
+	`
+
+	now := time.Now()
+	category, err := scanCategory(s.writePool.QueryRow(ctx, query,
+		uuid.New().String(), parentID, req.Slug, level, req.Names, now, now))
+	if err != nil {
+		return nil, slugError(err)
+	}
+
+	return category, nil
+}
+
+func (s *PgStore) UpdateCategory(ctx context.Context, slug string, req UpdateCategoryRequest) (*Category, error) {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		WITH c AS (
+			UPDATE categories SET
+				slug = COALESCE($1, slug),
+				names = COALESCE($2, names),
+				updated_at = $3
+			WHERE slug = $4
+			RETURNING *
+		)
+		SELECT ` + categoryColumns + `
+		FROM c
+		LEFT JOIN categories p ON p.id = c.parent_id
+	`
+
+	var names map[string]string
+	if len(req.Names) > 0 {
+		names = req.Names
+	}
+
+	category, err := scanCategory(tx.QueryRow(ctx, query, req.Slug, names, time.Now(), slug))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, slugError(err)
+	}
+
+	// Specialists follow the new slug by their foreign key, services are matched by value
+	if category.Slug != slug {
+		if _, err := tx.Exec(ctx, `UPDATE services SET category = $1 WHERE category = $2`, category.Slug, slug); err != nil {
+			return nil, fmt.Errorf("failed to rename category of services: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *PgStore) DeleteCategory(ctx context.Context, slug string) error {
+	var inUse bool
+	err := s.writePool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM categories c JOIN categories p ON p.id = c.parent_id WHERE p.slug = $1)
+			OR EXISTS (SELECT 1 FROM specialists WHERE area_type = $1)
+			OR EXISTS (SELECT 1 FROM services WHERE category = $1)
+	`, slug).Scan(&inUse)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrCategoryInUse
+	}
+
+	result, err := s.writePool.Exec(ctx, `DELETE FROM categories WHERE slug = $1`, slug)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+// Tree nests the categories under their parents. Roots and children are ordered by slug.
+func Tree(categories []*Category) []*Category {
+	bySlug := make(map[string]*Category, len(categories))
+	for _, category := range categories {
+		node := *category
+		node.Children = nil
+		bySlug[node.Slug] = &node
+	}
+
+	var roots []*Category
+	for _, category := range categories {
+		node := bySlug[category.Slug]
+		if parent, ok := bySlug[derefSlug(category.ParentSlug)]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sortTree(roots)
+	return roots
+}
+
+func sortTree(nodes []*Category) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Slug < nodes[j].Slug })
+	for _, node := range nodes {
+		sortTree(node.Children)
+	}
+}
+
+// Subtree returns the slug with the slugs of all categories below it.
+func Subtree(categories []*Category, slug string) []string {
+	slugs := []string{slug}
+	for i := 0; i < len(slugs); i++ {
+		for _, category := range categories {
+			if derefSlug(category.ParentSlug) == slugs[i] {
+				slugs = append(slugs, category.Slug)
+			}
+		}
+	}
+	return slugs
+}
+
+func derefSlug(slug *string) string {
+	if slug == nil {
+		return ""
+	}
+	return *slug
+}
@@ -0,0 +1,70 @@
+package taxonomy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testCategories() []*Category {
+	parent := func(slug string) *string { return &slug }
+	return []*Category{
+		{Slug: "sport", Level: LevelArea},
+		{Slug: "makeup", Level: LevelArea},
+		{Slug: "yoga", ParentSlug: parent("sport"), Level: LevelCategory},
+		{Slug: "bridal", ParentSlug: parent("makeup"), Level: LevelCategory},
+		{Slug: "hatha", ParentSlug: parent("yoga"), Level: LevelSubcategory},
+		{Slug: "ashtanga", ParentSlug: parent("yoga"), Level: LevelSubcategory},
+	}
+}
+
+func TestTree(t *testing.T) {
+	categories := testCategories()
+	roots := Tree(categories)
+
+	var got []string
+	var walk func(nodes []*Category, prefix string)
+	walk = func(nodes []*Category, prefix string) {
+		for _, node := range nodes {
+			got = append(got, prefix+node.Slug)
+			walk(node.Children, prefix+node.Slug+"/")
+		}
+	}
+	walk(roots, "")
+
+	want := []string{"makeup", "makeup/bridal", "sport", "sport/yoga", "sport/yoga/ashtanga", "sport/yoga/hatha"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tree() = %v, want %v", got, want)
+	}
+	if categories[0].Children != nil {
+		t.Errorf("Tree() modified its input")
+	}
+}
+
+func TestSubtree(t *testing.T) {
+	tests := []struct {
+		slug string
+		want []string
+	}{
+		{slug: "sport", want: []string{"sport", "yoga", "hatha", "ashtanga"}},
+		{slug: "yoga", want: []string{"yoga", "hatha", "ashtanga"}},
+		{slug: "hatha", want: []string{"hatha"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			if got := Subtree(testCategories(), tt.slug); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Subtree() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategory_DisplayName(t *testing.T) {
+	category := &Category{Names: map[string]string{"en": "Makeup", "de": "Make-up"}}
+	if got := category.DisplayName("de"); got != "Make-up" {
+		t.Errorf("DisplayName(de) = %s, want Make-up", got)
+	}
+	if got := category.DisplayName("fr"); got != "Makeup" {
+		t.Errorf("DisplayName(fr) = %s, want the default locale", got)
+	}
+}
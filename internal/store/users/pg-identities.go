@@ -0,0 +1,83 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	identitiesTableName = "user_identities"
+	uniqueViolationCode = "23505"
+)
+
+func (s *PgStore) GetUserIdByIdentity(ctx context.Context, provider, subject string) (string, error) {
+	return getUserIdByIdentity(ctx, s.readPool, provider, subject)
+}
+
+func getUserIdByIdentity(ctx context.Context, pool *pgxpool.Pool, provider, subject string) (string, error) {
+	query := fmt.Sprintf(`SELECT user_id FROM %s WHERE provider = $1 AND subject = $2`, identitiesTableName)
+
+	var id string
+	err := pool.QueryRow(ctx, query, provider, subject).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrUserNotFound
+		}
+		return "", fmt.Errorf("failed to get user id by identity: %w", err)
+	}
+
+	return id, nil
+}
+
+// SignUp links the identity to the user with its email, or creates u if there is none. It returns the ID of
+// the user and whether u was created. Callers must only sign up emails verified by the provider, otherwise
+// anybody could take over an account by registering its email elsewhere.
+func (s *PgStore) SignUp(ctx context.Context, identity Identity, u *User) (string, bool, error) {
+	tx, err := s.writePool.Begin(ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID string
+	created := false
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE email = $1 ORDER BY created_at LIMIT 1`, tableName)
+	err = tx.QueryRow(ctx, query, identity.Email).Scan(&userID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		userID, created = uuid.New().String(), true
+		query := fmt.Sprintf(`
+			INSERT INTO %s (id, username, email, firstname, lastname, phone, picture_url)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, tableName)
+		if _, err := tx.Exec(ctx, query, userID, u.Username, identity.Email, u.FirstName, u.LastName, u.Phone, u.PictureURL); err != nil {
+			return "", false, fmt.Errorf("failed to create user: %w", err)
+		}
+	case err != nil:
+		return "", false, fmt.Errorf("failed to get user id by email: %w", err)
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (provider, subject, user_id, email) VALUES ($1, $2, $3, $4)`, identitiesTableName)
+	if _, err := tx.Exec(ctx, query, identity.Provider, identity.Subject, userID, identity.Email); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			// A concurrent first login signed the identity up already. It was just committed, so it is read
+			// from the primary: a replica may not have it yet
+			tx.Rollback(ctx)
+			userID, err := getUserIdByIdentity(ctx, s.writePool, identity.Provider, identity.Subject)
+			return userID, false, err
+		}
+		return "", false, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", false, fmt.Errorf("failed to commit sign up: %w", err)
+	}
+	return userID, created, nil
+}
@@ -35,11 +35,11 @@ func (s *PgStore) CreateUser(ctx context.Context, u *User) error {
 	}
 
 	query := fmt.Sprintf(`
-		INSERT INTO %s (id, username, email, firstname, lastname, phone)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO %s (id, username, email, firstname, lastname, phone, picture_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`, tableName)
 
-	_, err := s.writePool.Exec(ctx, query, u.ID, u.Username, u.Email, u.FirstName, u.LastName, u.Phone)
+	_, err := s.writePool.Exec(ctx, query, u.ID, u.Username, u.Email, u.FirstName, u.LastName, u.Phone, u.PictureURL)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -48,7 +48,9 @@ func (s *PgStore) CreateUser(ctx context.Context, u *User) error {
 }
 
 func (s *PgStore) GetByID(ctx context.Context, userID string) (*User, error) {
-	query := fmt.Sprintf(`SELECT FROM %s id, username, email, firstname, lastname, phone where id = $1`, tableName)
+	query := fmt.Sprintf(`
+		SELECT id, username, email, firstname, lastname, phone, COALESCE(picture_url, '') FROM %s WHERE id = $1
+	`, tableName)
 
 	var user User
 	err := s.readPool.QueryRow(ctx, query, userID).Scan(
@@ -58,6 +60,7 @@ func (s *PgStore) GetByID(ctx context.Context, userID string) (*User, error) {
 		&user.FirstName,
 		&user.LastName,
 		&user.Phone,
+		&user.PictureURL,
 	)
 
 	if err != nil {
@@ -86,7 +89,9 @@ func (s *PgStore) GetUserIdByEmail(ctx context.Context, email string) (string, e
 }
 
 func (s *PgStore) GetUser(ctx context.Context, userID string) (*User, error) {
-	query := fmt.Sprintf(`SELECT id, username, email, firstname, lastname, phone FROM %s WHERE id = $1`, tableName)
+	query := fmt.Sprintf(`
+		SELECT id, username, email, firstname, lastname, phone, COALESCE(picture_url, '') FROM %s WHERE id = $1
+	`, tableName)
 
 	var user User
 	err := s.readPool.QueryRow(ctx, query, userID).Scan(
@@ -96,6 +101,7 @@ func (s *PgStore) GetUser(ctx context.Context, userID string) (*User, error) {
 		&user.FirstName,
 		&user.LastName,
 		&user.Phone,
+		&user.PictureURL,
 	)
 
 	if err != nil {
@@ -3,12 +3,24 @@ package users
 import "context"
 
 type User struct {
-	ID        string `json:"id"`
-	Username  string `json:"username"`
-	FirstName string `json:"firstName"`
-	LastName  string `json:"lastName"`
-	Email     string `json:"email"`
-	Phone     string `json:"phone"`
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	FirstName  string `json:"firstName"`
+	LastName   string `json:"lastName"`
+	Email      string `json:"email"`
+	Phone      string `json:"phone"`
+	PictureURL string `json:"pictureUrl"`
+}
+
+// ProviderGoogle is the identity provider of Google logins.
+const ProviderGoogle = "google"
+
+// Identity is an account of a user at an identity provider, such as a Google account.
+type Identity struct {
+	Provider string
+	// Subject is the ID of the account at the provider.
+	Subject string
+	Email   string
 }
 
 type Store interface {
@@ -18,4 +30,6 @@ type Store interface {
 	GetUser(context.Context, string) (*User, error)
 	UpdateUser(context.Context, *User) error
 	DeleteUser(context.Context, string) error
+	GetUserIdByIdentity(ctx context.Context, provider, subject string) (string, error)
+	SignUp(ctx context.Context, identity Identity, u *User) (string, bool, error)
 }
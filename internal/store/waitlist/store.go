@@ -0,0 +1,263 @@
+package waitlist
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const entryColumns = `id, user_id, business_id, service_id, specialist_id, window_start, window_end, status, ` +
+	`booking_id, created_at, updated_at`
+
+type Status string
+
+const (
+	// StatusWaiting entries wait for a matching slot to be freed.
+	StatusWaiting Status = "waiting"
+	// StatusOffered entries hold a freed slot, see BookingID.
+	StatusOffered   Status = "offered"
+	StatusFulfilled Status = "fulfilled"
+	StatusExpired   Status = "expired"
+	StatusCancelled Status = "cancelled"
+)
+
+var ErrEntryNotFound = errors.New("waitlist entry not found")
+
+// Entry is the interest of a customer in any slot of a service starting within [WindowStart, WindowEnd).
+type Entry struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	BusinessID   string    `json:"business_id"`
+	ServiceID    string    `json:"service_id"`
+	SpecialistID *string   `json:"specialist_id,omitempty"`
+	WindowStart  time.Time `json:"window_start"`
+	WindowEnd    time.Time `json:"window_end"`
+	Status       Status    `json:"status"`
+	// BookingID is the held booking offered to the customer.
+	BookingID *string   `json:"booking_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateEntryRequest struct {
+	UserID       string    `json:"-"`
+	BusinessID   string    `json:"business_id"`
+	ServiceID    string    `json:"service_id"`
+	SpecialistID *string   `json:"specialist_id,omitempty"`
+	WindowStart  time.Time `json:"window_start"`
+	WindowEnd    time.Time `json:"window_end"`
+}
+
+// FreedSlot is a slot given up by a booking, to be offered to the waitlist.
+type FreedSlot struct {
+	BusinessID   string
+	ServiceID    string
+	SpecialistID *string
+	StartTime    time.Time
+	// ExcludeUserID is the customer who gave the slot up, it is not offered back to them.
+	ExcludeUserID *string
+}
+
+type Store interface {
+	CreateEntry(ctx context.Context, req CreateEntryRequest) (*Entry, error)
+	GetEntry(ctx context.Context, id string) (*Entry, error)
+	GetEntryByBooking(ctx context.Context, bookingID string) (*Entry, error)
+	ListUserEntries(ctx context.Context, userID string) ([]*Entry, error)
+	NextEntry(ctx context.Context, slot FreedSlot) (*Entry, error)
+	MarkOffered(ctx context.Context, id, bookingID string) error
+	UpdateStatus(ctx context.Context, id string, from, to Status) (*Entry, error)
+}
+
+type PgStore struct {
+	readPool  *pgxpool.Pool
+	writePool *pgxpool.Pool
+}
+
+var _ Store = NewStore(nil, nil)
+
+func NewStore(readPool, writePool *pgxpool.Pool) *PgStore {
+	return &PgStore{
+		readPool:  readPool,
+		writePool: writePool,
+	}
+}
+
+func scanEntry(row pgx.Row) (*Entry, error) {
+	var entry Entry
+	err := row.Scan(
+		&entry.ID,
+		&entry.UserID,
+		&entry.BusinessID,
+		&entry.ServiceID,
+		&entry.SpecialistID,
+		&entry.WindowStart,
+		&entry.WindowEnd,
+		&entry.Status,
+		&entry.BookingID,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (s *PgStore) CreateEntry(ctx context.Context, req CreateEntryRequest) (*Entry, error) {
+	query := `
+		INSERT INTO waitlist_entries (
+			id, user_id, business_id, service_id, specialist_id, window_start, window_end, status, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		) RETURNING ` + entryColumns
+
+	now := time.Now()
+	return scanEntry(s.writePool.QueryRow(ctx, query,
+		uuid.New().String(),
+		req.UserID,
+		req.BusinessID,
+		req.ServiceID,
+		req.SpecialistID,
+		req.WindowStart,
+		req.WindowEnd,
+		StatusWaiting,
+		now,
+		now,
+	))
+}
+
+func (s *PgStore) GetEntry(ctx context.Context, id string) (*Entry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM waitlist_entries
+		WHERE id = $1
+	`
+
+	entry, err := scanEntry(s.readPool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// GetEntryByBooking returns the entry the held booking was offered to, or nil for regular bookings.
+func (s *PgStore) GetEntryByBooking(ctx context.Context, bookingID string) (*Entry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM waitlist_entries
+		WHERE booking_id = $1
+	`
+
+	entry, err := scanEntry(s.writePool.QueryRow(ctx, query, bookingID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// ListUserEntries returns the waitlist entries of a customer, the newest first.
+func (s *PgStore) ListUserEntries(ctx context.Context, userID string) ([]*Entry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM waitlist_entries
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.readPool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*Entry, 0)
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// NextEntry returns the longest waiting entry the freed slot matches, or nil. Entries without a specialist
+// accept any specialist.
+func (s *PgStore) NextEntry(ctx context.Context, slot FreedSlot) (*Entry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM waitlist_entries
+		WHERE status = $1
+			AND business_id = $2
+			AND service_id = $3
+			AND (specialist_id IS NULL OR specialist_id IS NOT DISTINCT FROM $4)
+			AND window_start <= $5 AND $5 < window_end
+			AND ($6::uuid IS NULL OR user_id <> $6)
+		ORDER BY created_at, id
+		LIMIT 1
+	`
+
+	entry, err := scanEntry(s.writePool.QueryRow(ctx, query,
+		StatusWaiting, slot.BusinessID, slot.ServiceID, slot.SpecialistID, slot.StartTime, slot.ExcludeUserID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find waitlist entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// MarkOffered links a waiting entry to the booking held for it.
+func (s *PgStore) MarkOffered(ctx context.Context, id, bookingID string) error {
+	query := `
+		UPDATE waitlist_entries SET status = $1, booking_id = $2, updated_at = $3
+		WHERE id = $4 AND status = $5
+	`
+
+	result, err := s.writePool.Exec(ctx, query, StatusOffered, bookingID, time.Now(), id, StatusWaiting)
+	if err != nil {
+		return fmt.Errorf("failed to mark waitlist entry as offered: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrEntryNotFound
+	}
+
+	return nil
+}
+
+// UpdateStatus moves an entry from one status to another. It fails with ErrEntryNotFound when the entry
+// is not in the from status anymore, so concurrent changes cannot both succeed.
+func (s *PgStore) UpdateStatus(ctx context.Context, id string, from, to Status) (*Entry, error) {
+	query := `
+		UPDATE waitlist_entries SET status = $1, updated_at = $2
+		WHERE id = $3 AND status = $4
+		RETURNING ` + entryColumns
+
+	entry, err := scanEntry(s.writePool.QueryRow(ctx, query, to, time.Now(), id, from))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEntryNotFound
+		}
+		return nil, fmt.Errorf("failed to update waitlist entry: %w", err)
+	}
+
+	return entry, nil
+}
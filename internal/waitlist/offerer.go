@@ -0,0 +1,160 @@
+package waitlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/waitlist"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Offerer hands slots freed by cancelled bookings to the waitlist. The first matching entry gets the slot
+// as a held booking; a hold that is declined or not claimed within holdTTL passes to the next entry.
+type Offerer struct {
+	bookingsStore bookings.Store
+	waitlistStore waitlist.Store
+	holdTTL       time.Duration
+}
+
+func NewOfferer(bookingsStore bookings.Store, waitlistStore waitlist.Store, holdTTL time.Duration) *Offerer {
+	return &Offerer{
+		bookingsStore: bookingsStore,
+		waitlistStore: waitlistStore,
+		holdTTL:       holdTTL,
+	}
+}
+
+// OfferSlot offers the slot of the freed booking to the longest waiting matching entry, if there is one.
+func (o *Offerer) OfferSlot(ctx context.Context, freed *bookings.Booking) error {
+	if !freed.StartTime.After(time.Now()) {
+		return nil
+	}
+
+	slot := waitlist.FreedSlot{
+		BusinessID:    freed.BusinessID,
+		ServiceID:     freed.ServiceID,
+		SpecialistID:  freed.SpecialistID,
+		StartTime:     freed.StartTime,
+		ExcludeUserID: freed.UserID,
+	}
+
+	entry, err := o.waitlistStore.NextEntry(ctx, slot)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	// The hold takes over the services of the freed booking; bookings from lists come without them
+	items := freed.Items
+	if items == nil {
+		booking, err := o.bookingsStore.GetBooking(ctx, freed.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get items of booking %s: %w", freed.ID, err)
+		}
+		if booking != nil {
+			items = booking.Items
+		}
+	}
+
+	expiresAt := time.Now().Add(o.holdTTL)
+	hold, err := o.bookingsStore.CreateBooking(ctx, bookings.CreateBookingRequest{
+		UserID:        entry.UserID,
+		BusinessID:    freed.BusinessID,
+		SpecialistID:  freed.SpecialistID,
+		ServiceID:     freed.ServiceID,
+		StartTime:     freed.StartTime,
+		EndTime:       freed.EndTime,
+		HoldExpiresAt: &expiresAt,
+		Items:         items,
+	})
+	if err != nil {
+		if errors.Is(err, bookings.ErrAlreadyAttending) {
+			// The customer booked the class session meanwhile, which settles their entry; the spot goes on
+			if _, err := o.waitlistStore.UpdateStatus(ctx, entry.ID, waitlist.StatusWaiting, waitlist.StatusFulfilled); err != nil &&
+				!errors.Is(err, waitlist.ErrEntryNotFound) {
+				return err
+			}
+			return o.OfferSlot(ctx, freed)
+		}
+		if errors.Is(err, bookings.ErrBookingOverlap) || errors.Is(err, bookings.ErrSessionFull) {
+			// Somebody booked the slot in the meantime, there is nothing to offer anymore
+			return nil
+		}
+		return fmt.Errorf("failed to hold slot for waitlist entry %s: %w", entry.ID, err)
+	}
+
+	if err := o.waitlistStore.MarkOffered(ctx, entry.ID, hold.ID); err != nil {
+		// The entry was left in the meantime; release the hold so the slot is not lost
+		if _, tErr := o.bookingsStore.TransitionBooking(ctx, hold.ID, bookings.StatusExpired, ""); tErr != nil {
+			return fmt.Errorf("failed to release hold %s: %w", hold.ID, tErr)
+		}
+		if errors.Is(err, waitlist.ErrEntryNotFound) {
+			return o.OfferSlot(ctx, freed)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseHold expires a held booking, marks its entry with status, and passes the slot to the next entry.
+// The store expires the entry together with the hold; a status other than expired is set afterwards.
+func (o *Offerer) ReleaseHold(ctx context.Context, hold *bookings.Booking, actorUserID string, status waitlist.Status) error {
+	if _, err := o.bookingsStore.TransitionBooking(ctx, hold.ID, bookings.StatusExpired, actorUserID); err != nil {
+		return err
+	}
+
+	if status != waitlist.StatusExpired {
+		entry, err := o.waitlistStore.GetEntryByBooking(ctx, hold.ID)
+		if err != nil {
+			return err
+		}
+		if entry != nil {
+			if _, err := o.waitlistStore.UpdateStatus(ctx, entry.ID, waitlist.StatusExpired, status); err != nil &&
+				!errors.Is(err, waitlist.ErrEntryNotFound) {
+				return err
+			}
+		}
+	}
+
+	return o.OfferSlot(ctx, hold)
+}
+
+// ExpireHolds releases the holds that were not claimed in time, checkout holds and waitlist offers alike.
+// Their slots are offered to the waitlist.
+func (o *Offerer) ExpireHolds(ctx context.Context, now time.Time) error {
+	holds, err := o.bookingsStore.ListExpiredHolds(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to list expired holds: %w", err)
+	}
+
+	for _, hold := range holds {
+		err := o.ReleaseHold(ctx, hold, "", waitlist.StatusExpired)
+		// A hold claimed at the last moment is not expired anymore
+		if err != nil && !errors.Is(err, bookings.ErrInvalidTransition) {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to expire hold %s", hold.ID)
+		}
+	}
+
+	return nil
+}
+
+// Run expires holds every interval until the context is done.
+func (o *Offerer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := o.ExpireHolds(ctx, now); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to expire waitlist holds")
+			}
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package waitlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"booking-service/internal/store/bookings"
+	"booking-service/internal/store/waitlist"
+)
+
+// fakeBookings keeps bookings in memory. Like the store, a hold leaving the held status settles the
+// waitlist entry it was offered to.
+type fakeBookings struct {
+	bookings.Store
+	waitlist  *fakeWaitlist
+	bookings  map[string]*bookings.Booking
+	expired   []*bookings.Booking
+	createErr error
+	// attending are the customers already booked into the class session of every slot.
+	attending []string
+}
+
+func (f *fakeBookings) CreateBooking(_ context.Context, req bookings.CreateBookingRequest) (*bookings.Booking, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	if slices.Contains(f.attending, req.UserID) {
+		return nil, bookings.ErrAlreadyAttending
+	}
+	booking := &bookings.Booking{
+		ID:            fmt.Sprintf("hold-%d", len(f.bookings)+1),
+		UserID:        &req.UserID,
+		BusinessID:    req.BusinessID,
+		ServiceID:     req.ServiceID,
+		StartTime:     req.StartTime,
+		EndTime:       req.EndTime,
+		Status:        bookings.StatusHeld,
+		HoldExpiresAt: req.HoldExpiresAt,
+		Items:         req.Items,
+	}
+	f.bookings[booking.ID] = booking
+	return booking, nil
+}
+
+func (f *fakeBookings) GetBooking(_ context.Context, id string) (*bookings.Booking, error) {
+	return f.bookings[id], nil
+}
+
+func (f *fakeBookings) TransitionBooking(_ context.Context, id string, to bookings.Status, _ string) (*bookings.Booking, error) {
+	booking := f.bookings[id]
+	if booking == nil {
+		return nil, bookings.ErrBookingNotFound
+	}
+	if !bookings.CanTransition(booking.Status, to) {
+		return nil, fmt.Errorf("%w: %s to %s", bookings.ErrInvalidTransition, booking.Status, to)
+	}
+	if booking.Status == bookings.StatusHeld {
+		for _, entry := range f.waitlist.entries {
+			if entry.Status != waitlist.StatusOffered || entry.BookingID == nil || *entry.BookingID != id {
+				continue
+			}
+			switch to {
+			case bookings.StatusPending:
+				entry.Status = waitlist.StatusFulfilled
+			case bookings.StatusCancelledByBusiness:
+				entry.Status, entry.BookingID = waitlist.StatusWaiting, nil
+			default:
+				entry.Status = waitlist.StatusExpired
+			}
+		}
+	}
+	booking.Status = to
+	return booking, nil
+}
+
+func (f *fakeBookings) ListExpiredHolds(context.Context, time.Time) ([]*bookings.Booking, error) {
+	return f.expired, nil
+}
+
+type fakeWaitlist struct {
+	waitlist.Store
+	entries []*waitlist.Entry
+}
+
+func (f *fakeWaitlist) NextEntry(_ context.Context, slot waitlist.FreedSlot) (*waitlist.Entry, error) {
+	for _, entry := range f.entries {
+		if entry.Status != waitlist.StatusWaiting || entry.ServiceID != slot.ServiceID ||
+			slot.StartTime.Before(entry.WindowStart) || !slot.StartTime.Before(entry.WindowEnd) ||
+			(slot.ExcludeUserID != nil && entry.UserID == *slot.ExcludeUserID) {
+			continue
+		}
+		return entry, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeWaitlist) MarkOffered(_ context.Context, id, bookingID string) error {
+	for _, entry := range f.entries {
+		if entry.ID == id && entry.Status == waitlist.StatusWaiting {
+			entry.Status, entry.BookingID = waitlist.StatusOffered, &bookingID
+			return nil
+		}
+	}
+	return waitlist.ErrEntryNotFound
+}
+
+func (f *fakeWaitlist) GetEntryByBooking(_ context.Context, bookingID string) (*waitlist.Entry, error) {
+	for _, entry := range f.entries {
+		if entry.BookingID != nil && *entry.BookingID == bookingID {
+			return entry, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeWaitlist) UpdateStatus(_ context.Context, id string, from, to waitlist.Status) (*waitlist.Entry, error) {
+	for _, entry := range f.entries {
+		if entry.ID == id && entry.Status == from {
+			entry.Status = to
+			return entry, nil
+		}
+	}
+	return nil, waitlist.ErrEntryNotFound
+}
+
+const testHoldTTL = 15 * time.Minute
+
+func newTestOfferer(entries ...*waitlist.Entry) (*Offerer, *fakeBookings, *fakeWaitlist) {
+	waitlistStore := &fakeWaitlist{entries: entries}
+	bookingsStore := &fakeBookings{waitlist: waitlistStore, bookings: make(map[string]*bookings.Booking)}
+	return NewOfferer(bookingsStore, waitlistStore, testHoldTTL), bookingsStore, waitlistStore
+}
+
+func waitingEntry(id, userID string, slotStart time.Time) *waitlist.Entry {
+	return &waitlist.Entry{
+		ID:          id,
+		UserID:      userID,
+		ServiceID:   "service",
+		WindowStart: slotStart.Add(-time.Hour),
+		WindowEnd:   slotStart.Add(time.Hour),
+		Status:      waitlist.StatusWaiting,
+	}
+}
+
+func freedBooking(userID string, start time.Time) *bookings.Booking {
+	return &bookings.Booking{ID: "freed", UserID: &userID, ServiceID: "service", StartTime: start, EndTime: start.Add(time.Hour)}
+}
+
+func TestOfferer_OfferSlot(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour)
+
+	t.Run("holds the slot for the longest waiting entry", func(t *testing.T) {
+		offerer, bookingsStore, waitlistStore := newTestOfferer(waitingEntry("own", "customer", start), waitingEntry("next", "other", start))
+
+		before := time.Now()
+		if err := offerer.OfferSlot(context.Background(), freedBooking("customer", start)); err != nil {
+			t.Fatalf("OfferSlot() error = %v", err)
+		}
+
+		next := waitlistStore.entries[1]
+		if next.Status != waitlist.StatusOffered || next.BookingID == nil {
+			t.Fatalf("expected the entry of another customer to be offered the slot, got %s", next.Status)
+		}
+		hold := bookingsStore.bookings[*next.BookingID]
+		if hold.Status != bookings.StatusHeld || *hold.UserID != "other" || !hold.StartTime.Equal(start) {
+			t.Errorf("unexpected hold %+v", hold)
+		}
+		if hold.HoldExpiresAt == nil || hold.HoldExpiresAt.Before(before.Add(testHoldTTL)) {
+			t.Errorf("expected the hold to expire after the hold TTL, got %v", hold.HoldExpiresAt)
+		}
+	})
+
+	t.Run("the hold takes over the services of the freed booking", func(t *testing.T) {
+		offerer, bookingsStore, waitlistStore := newTestOfferer(waitingEntry("next", "other", start))
+		freed := freedBooking("customer", start)
+		freed.Items = []bookings.BookingItem{{ServiceID: "service", DurationMinutes: 30}, {ServiceID: "nails", DurationMinutes: 30}}
+		// Bookings from lists come without their items, which are loaded then
+		bookingsStore.bookings[freed.ID] = freed
+		listed := *freed
+		listed.Items = nil
+
+		if err := offerer.OfferSlot(context.Background(), &listed); err != nil {
+			t.Fatalf("OfferSlot() error = %v", err)
+		}
+
+		hold := bookingsStore.bookings[*waitlistStore.entries[0].BookingID]
+		if len(hold.Items) != 2 || hold.Items[1].ServiceID != "nails" {
+			t.Errorf("expected the hold to keep both services, got %+v", hold.Items)
+		}
+	})
+
+	t.Run("past slots are not offered", func(t *testing.T) {
+		offerer, bookingsStore, _ := newTestOfferer(waitingEntry("next", "other", start))
+
+		if err := offerer.OfferSlot(context.Background(), freedBooking("customer", time.Now().Add(-time.Hour))); err != nil {
+			t.Fatalf("OfferSlot() error = %v", err)
+		}
+		if len(bookingsStore.bookings) != 0 {
+			t.Errorf("expected no hold, got %d", len(bookingsStore.bookings))
+		}
+	})
+
+	t.Run("a slot booked in the meantime is not offered", func(t *testing.T) {
+		offerer, bookingsStore, waitlistStore := newTestOfferer(waitingEntry("next", "other", start))
+		bookingsStore.createErr = bookings.ErrBookingOverlap
+
+		if err := offerer.OfferSlot(context.Background(), freedBooking("customer", start)); err != nil {
+			t.Fatalf("OfferSlot() error = %v", err)
+		}
+		if status := waitlistStore.entries[0].Status; status != waitlist.StatusWaiting {
+			t.Errorf("expected the entry to keep waiting, got %s", status)
+		}
+	})
+
+	t.Run("an entry of a customer attending the session already is fulfilled", func(t *testing.T) {
+		offerer, bookingsStore, waitlistStore := newTestOfferer(waitingEntry("attending", "alice", start), waitingEntry("next", "bob", start))
+		bookingsStore.attending = []string{"alice"}
+
+		if err := offerer.OfferSlot(context.Background(), freedBooking("customer", start)); err != nil {
+			t.Fatalf("OfferSlot() error = %v", err)
+		}
+
+		attending, next := waitlistStore.entries[0], waitlistStore.entries[1]
+		if attending.Status != waitlist.StatusFulfilled {
+			t.Errorf("expected the entry of the attending customer to be fulfilled, got %s", attending.Status)
+		}
+		if next.Status != waitlist.StatusOffered || next.BookingID == nil {
+			t.Errorf("expected the slot to pass to the next entry, got %s", next.Status)
+		}
+	})
+}
+
+func TestOfferer_ReleaseHold(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour)
+	offerer, bookingsStore, waitlistStore := newTestOfferer(waitingEntry("first", "alice", start), waitingEntry("second", "bob", start))
+	ctx := context.Background()
+
+	if err := offerer.OfferSlot(ctx, freedBooking("customer", start)); err != nil {
+		t.Fatalf("OfferSlot() error = %v", err)
+	}
+	first, second := waitlistStore.entries[0], waitlistStore.entries[1]
+	hold := bookingsStore.bookings[*first.BookingID]
+
+	if err := offerer.ReleaseHold(ctx, hold, "alice", waitlist.StatusCancelled); err != nil {
+		t.Fatalf("ReleaseHold() error = %v", err)
+	}
+
+	if hold.Status != bookings.StatusExpired {
+		t.Errorf("expected the hold to expire, got %s", hold.Status)
+	}
+	if first.Status != waitlist.StatusCancelled {
+		t.Errorf("expected the declined entry to be cancelled, got %s", first.Status)
+	}
+	if second.Status != waitlist.StatusOffered || second.BookingID == nil || *second.BookingID == hold.ID {
+		t.Errorf("expected the slot to pass to the next entry, got %s", second.Status)
+	}
+
+	if err := offerer.ReleaseHold(ctx, hold, "alice", waitlist.StatusCancelled); !errors.Is(err, bookings.ErrInvalidTransition) {
+		t.Errorf("expected releasing a released hold to be an invalid transition, got %v", err)
+	}
+}
+
+func TestOfferer_ExpireHolds(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour)
+	offerer, bookingsStore, waitlistStore := newTestOfferer(waitingEntry("first", "alice", start))
+	ctx := context.Background()
+
+	if err := offerer.OfferSlot(ctx, freedBooking("customer", start)); err != nil {
+		t.Fatalf("OfferSlot() error = %v", err)
+	}
+	entry := waitlistStore.entries[0]
+	hold := bookingsStore.bookings[*entry.BookingID]
+	// A hold claimed at the last moment is listed too, but cannot be expired anymore
+	claimed := &bookings.Booking{ID: "claimed", Status: bookings.StatusPending, StartTime: start}
+	bookingsStore.bookings[claimed.ID] = claimed
+	bookingsStore.expired = []*bookings.Booking{claimed, hold}
+
+	if err := offerer.ExpireHolds(ctx, time.Now()); err != nil {
+		t.Fatalf("ExpireHolds() error = %v", err)
+	}
+
+	if hold.Status != bookings.StatusExpired || entry.Status != waitlist.StatusExpired {
+		t.Errorf("expected the hold and its entry to expire, got %s and %s", hold.Status, entry.Status)
+	}
+	if claimed.Status != bookings.StatusPending {
+		t.Errorf("expected the claimed booking to stay pending, got %s", claimed.Status)
+	}
+}
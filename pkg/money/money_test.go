@@ -0,0 +1,69 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		code    string
+		digits  int
+		wantErr bool
+	}{
+		{code: "USD", digits: 2},
+		{code: "JPY", digits: 0},
+		{code: "KWD", digits: 3},
+		{code: "usd", wantErr: true},
+		{code: "ABC", wantErr: true},
+		{code: "XAU", wantErr: true},
+		{code: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			currency, err := ParseCurrency(tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCurrency(%q) error = %v, wantErr %v", tt.code, err, tt.wantErr)
+			}
+			if err == nil && currency.Digits() != tt.digits {
+				t.Errorf("Digits() = %d, want %d", currency.Digits(), tt.digits)
+			}
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: New(1050, "USD"), want: "10.50 USD"},
+		{money: New(5, "USD"), want: "0.05 USD"},
+		{money: New(-250, "EUR"), want: "-2.50 EUR"},
+		{money: New(1050, "JPY"), want: "1050 JPY"},
+		{money: New(1500, "KWD"), want: "1.500 KWD"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("String() = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestSum(t *testing.T) {
+	// Ten times 0.10 is exactly 1.00, which float64 prices did not guarantee
+	amounts := make([]Money, 10)
+	for i := range amounts {
+		amounts[i] = New(10, "USD")
+	}
+	total, err := Sum(amounts...)
+	if err != nil || total != New(100, "USD") {
+		t.Errorf("Sum() = %v, %v, want 1.00 USD", total, err)
+	}
+
+	if _, err := Sum(New(100, "USD"), New(100, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sum() of mixed currencies error = %v, want ErrCurrencyMismatch", err)
+	}
+}
@@ -0,0 +1,99 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, expired or not signed by the service.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// signingMethod is the only algorithm tokens are issued and accepted with.
+var signingMethod = jwt.SigningMethodHS256
+
+// refreshTokenBytes is the entropy of refresh tokens.
+const refreshTokenBytes = 32
+
+// Claims are the claims of an access token.
+type Claims struct {
+	UserID string `json:"user_id"`
+	// SessionID is the session the token was issued for; the token is rejected once the session is revoked.
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Service issues and verifies the access tokens of the API, signed with a shared secret.
+type Service struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewService(secret string, ttl time.Duration) *Service {
+	return &Service{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}
+}
+
+// Issue returns a signed access token of the user's session, valid for the ttl of the service.
+func (s *Service) Issue(userID, sessionID string, now time.Time) (string, error) {
+	claims := Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(signingMethod, claims).SignedString(s.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// Verify checks the signature and expiry of the token and returns its claims.
+func (s *Service) Verify(tokenString string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}, jwt.WithValidMethods([]string{signingMethod.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.UserID == "" || claims.SessionID == "" {
+		return nil, fmt.Errorf("%w: missing user or session id", ErrInvalidToken)
+	}
+
+	return &claims, nil
+}
+
+// TTL is how long access tokens are valid.
+func (s *Service) TTL() time.Duration {
+	return s.ttl
+}
+
+// NewRefreshToken returns a random opaque refresh token together with the hash it is stored under.
+func NewRefreshToken() (string, string, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the hash a refresh token is stored and looked up by.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
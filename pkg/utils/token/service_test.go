@@ -0,0 +1,71 @@
+package token
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestService_Verify(t *testing.T) {
+	service := NewService("secret", time.Hour)
+	now := time.Now()
+
+	issued, err := service.Issue("user-1", "session-1", now)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	expired, _ := service.Issue("user-1", "session-1", now.Add(-2*time.Hour))
+	otherSecret, _ := NewService("other", time.Hour).Issue("user-1", "session-1", now)
+	noUser, _ := service.Issue("", "session-1", now)
+	noSession, _ := service.Issue("user-1", "", now)
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{UserID: "user-1"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "issued", token: issued},
+		{name: "expired", token: expired, wantErr: true},
+		{name: "other secret", token: otherSecret, wantErr: true},
+		{name: "no user", token: noUser, wantErr: true},
+		{name: "no session", token: noSession, wantErr: true},
+		{name: "unsigned", token: unsigned, wantErr: true},
+		{name: "bearer word", token: "Bearer", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := service.Verify(tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("Verify() error = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.UserID != "user-1" || claims.SessionID != "session-1" {
+				t.Errorf("claims = %q/%q, want user-1/session-1", claims.UserID, claims.SessionID)
+			}
+		})
+	}
+}
+
+func TestNewRefreshToken(t *testing.T) {
+	token, hash, err := NewRefreshToken()
+	if err != nil {
+		t.Fatalf("NewRefreshToken() error = %v", err)
+	}
+	if hash != HashRefreshToken(token) || hash == token {
+		t.Errorf("hash %q does not match token %q", hash, token)
+	}
+
+	other, _, _ := NewRefreshToken()
+	if other == token {
+		t.Errorf("NewRefreshToken() returned %q twice", token)
+	}
+}